@@ -0,0 +1,53 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// Cache is a key-value store with per item expiration.
+// It is implemented by *cache.Cache of github.com/patrickmn/go-cache.
+type Cache interface {
+	// Get returns the item and true if it exists and is not expired.
+	Get(key string) (any, bool)
+	// Set adds the item, the zero ttl means the default expiration of the cache.
+	Set(key string, value any, ttl time.Duration)
+	// Delete removes the item.
+	Delete(key string)
+}
+
+// NoopCache is a cache which never stores anything.
+type NoopCache struct{}
+
+var (
+	_ Cache = (*cache.Cache)(nil)
+	_ Cache = NoopCache{}
+)
+
+// Get implements Cache.
+func (NoopCache) Get(string) (any, bool) {
+	return nil, false
+}
+
+// Set implements Cache.
+func (NoopCache) Set(string, any, time.Duration) {}
+
+// Delete implements Cache.
+func (NoopCache) Delete(string) {}
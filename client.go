@@ -17,38 +17,173 @@ limitations under the License.
 package goproxmox
 
 import (
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/luthermonson/go-proxmox"
 	"github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
 )
 
 // APIClient Proxmox API client object.
 type APIClient struct {
 	*proxmox.Client
 
-	lastVMID  *cache.Cache
-	resources *cache.Cache
+	lastVMID  Cache
+	resources Cache
+
+	resourceGroup singleflight.Group
+	resourceStats resourceCacheStats
+	resourceMu    sync.Mutex
+	resourceGen   map[string]uint64
+	informer      atomic.Pointer[ResourceInformer]
+	vmIndex       *vmIndex
+
+	resourceTTL map[string]time.Duration
+	vmidTTL     time.Duration
+
+	metrics *metrics
+	tracer  trace.Tracer
+	limiter *limiter
+
+	timeouts         TimeoutPolicy
+	taskPollInterval time.Duration
+	cancelTasks      bool
+	taskLogHandler   TaskLogFunc
+	taskLogTail      int
+	taskConflicts    TaskConflictPolicy
+
+	lockWait          time.Duration
+	lockRetryInterval time.Duration
+
+	templateReplicas TemplateReplicaFunc
 }
 
 // NewAPIClient initializes a GO-Proxmox API client.
+//
+// API error responses are returned as *APIError. A custom HTTP client passed
+// with proxmox.WithHTTPClient replaces the client which implements it, along with
+// the metrics, tracing and rate limiting. The client then detects VM ID conflicts,
+// locks and missing VMs from the go-proxmox error text.
+// Use NewAPIClientWithOptions and WithHTTPClient instead.
 func NewAPIClient(url string, options ...proxmox.Option) (*APIClient, error) {
+	return NewAPIClientWithOptions(url, WithProxmoxOptions(options...))
+}
+
+// NewAPIClientWithOptions initializes a GO-Proxmox API client with the client options.
+func NewAPIClientWithOptions(url string, options ...ClientOption) (*APIClient, error) {
+	opts := defaultClientOptions()
+	for _, o := range options {
+		o(&opts)
+	}
+
+	httpClient := &http.Client{}
+	if opts.httpClient != nil {
+		*httpClient = *opts.httpClient
+	}
+
+	base := httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	tp := opts.tracerProvider
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+
+	tr := &transport{base: base, tracer: tp.Tracer(tracerName), failFast: opts.failFast}
+	if opts.rateLimit > 0 {
+		tr.rate = rate.NewLimiter(opts.rateLimit, opts.rateBurst)
+	}
+	httpClient.Transport = tr.httpTransport()
+
 	// ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	// defer cancel()
-	client := proxmox.NewClient(url, options...)
+	client := proxmox.NewClient(url, append([]proxmox.Option{proxmox.WithHTTPClient(httpClient)}, opts.proxmoxOptions...)...)
 
 	// _, err := client.Version(ctx)
 	// if err != nil {
 	// 	return nil, fmt.Errorf("unable to initialize proxmox api client: %w", err)
 	// }
 
-	return &APIClient{
-		Client:    client,
-		lastVMID:  cache.New(5*time.Minute, 10*time.Minute),
-		resources: cache.New(1*time.Minute, 10*time.Minute),
-	}, nil
+	c := &APIClient{
+		Client:      client,
+		resourceGen: map[string]uint64{},
+		vmIndex:     newVMIndex(),
+		lastVMID:    opts.vmidCache,
+		resources:   opts.resourceCache,
+		resourceTTL: opts.resourceTTL,
+		vmidTTL:     opts.vmidTTL,
+		tracer:      tr.tracer,
+		limiter:     newLimiter(opts.nodeConcurrency, opts.storageConcurrency, opts.failFast),
+
+		timeouts:         opts.timeouts,
+		taskPollInterval: opts.taskPollInterval,
+		cancelTasks:      opts.cancelTasks,
+		taskLogHandler:   opts.taskLogHandler,
+		taskLogTail:      opts.taskLogTail,
+		taskConflicts:    opts.taskConflicts,
+
+		lockWait:          opts.lockWait,
+		lockRetryInterval: opts.lockRetryInterval,
+
+		templateReplicas: opts.templateReplicas,
+	}
+
+	if c.lastVMID == nil {
+		c.lastVMID = cache.New(opts.vmidTTL, 10*time.Minute)
+	}
+
+	if c.resources == nil {
+		c.resources = cache.New(1*time.Minute, 10*time.Minute)
+	}
+
+	if opts.metricsRegisterer != nil {
+		cluster := opts.clusterName
+		if cluster == "" {
+			cluster = apiHost(url)
+		}
+
+		m, err := newMetrics(opts.metricsRegisterer, cluster, c)
+		if err != nil {
+			return nil, err
+		}
+
+		c.metrics = m
+		tr.metrics = m
+	}
+
+	return c, nil
+}
+
+// apiHost returns the host of the API URL, or the URL if it can't be parsed.
+func apiHost(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return apiURL
+	}
+
+	return u.Host
 }
 
+// flushResources drops the cached cluster resources of the type.
+// The following lookups do not join a request started before the flush.
 func (c *APIClient) flushResources(name string) { // nolint:unparam
+	c.resourceMu.Lock()
+	c.resourceGen[name]++
 	c.resources.Delete(name)
+
+	if inf := c.informer.Load(); inf != nil {
+		inf.store.invalidate(name)
+	}
+	c.resourceMu.Unlock()
+
+	c.resourceGroup.Forget(name)
 }
@@ -0,0 +1,142 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+	"github.com/sergelogvinov/go-proxmox/goproxmoxtest"
+)
+
+func TestNewAPIClientWithOptions_Cache(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		options  []goproxmox.ClientOption
+		requests int
+	}{
+		{
+			name:     "default",
+			requests: 1,
+		},
+		{
+			name:     "noop-cache",
+			options:  []goproxmox.ClientOption{goproxmox.WithCache(goproxmox.NoopCache{})},
+			requests: 3,
+		},
+		{
+			name:     "resource-ttl",
+			options:  []goproxmox.ClientOption{goproxmox.WithResourceTTL("vm", time.Nanosecond)},
+			requests: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := goproxmoxtest.NewServer()
+			t.Cleanup(srv.Close)
+
+			srv.AddNode("pve-1")
+			srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+			client, err := goproxmox.NewAPIClientWithOptions(srv.URL(), tt.options...)
+			require.NoError(t, err)
+
+			for range 3 {
+				_, err := client.GetVMByID(context.Background(), 100)
+				require.NoError(t, err)
+
+				time.Sleep(time.Millisecond)
+			}
+
+			assert.Equal(t, tt.requests, srv.RequestCount(http.MethodGet, "/cluster/resources"))
+		})
+	}
+}
+
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewAPIClient_HTTPClient(t *testing.T) {
+	t.Parallel()
+
+	srv := goproxmoxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddNode("pve-1")
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	tr := &countingTransport{}
+	hc := &http.Client{Transport: tr}
+
+	client, err := goproxmox.NewAPIClientWithOptions(srv.URL(), goproxmox.WithHTTPClient(hc))
+	require.NoError(t, err)
+
+	// The API errors are mapped by the client transport, which wraps the transport of the HTTP client.
+	_, err = client.GetVMConfig(context.Background(), 101)
+	require.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+
+	id, err := client.GetNextID(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 101, id)
+
+	assert.Positive(t, tr.requests.Load())
+	assert.Same(t, tr, hc.Transport)
+
+	// go-proxmox expects an *http.Transport in the client for websocket connections.
+	assert.NotPanics(t, func() {
+		_, _, _, _, err = client.VNCWebSocket("/nodes/pve-1/qemu/100/vncwebsocket", &proxmox.VNC{})
+	})
+	assert.Error(t, err)
+}
+
+func TestNewAPIClient_ProxmoxHTTPClient(t *testing.T) {
+	t.Parallel()
+
+	srv := goproxmoxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddNode("pve-1")
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	// The HTTP client replaces the one which returns APIError, the errors of go-proxmox are classified by their text.
+	client, err := goproxmox.NewAPIClient(srv.URL(), proxmox.WithHTTPClient(&http.Client{Transport: &countingTransport{}}))
+	require.NoError(t, err)
+
+	id, err := client.GetNextID(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 101, id)
+}
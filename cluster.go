@@ -19,10 +19,10 @@ package goproxmox
 import (
 	"context"
 	"fmt"
-	"time"
+	"sync/atomic"
 
 	"github.com/luthermonson/go-proxmox"
-	"github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // GetHAGroupList retrieves the list of HA groups in the cluster.
@@ -35,31 +35,108 @@ func (c *APIClient) GetHAGroupList(ctx context.Context) (groups []*HAGroup, err
 	return groups, nil
 }
 
-func (c *APIClient) getResources(ctx context.Context, name string) (proxmox.ClusterResources, error) {
-	resources := proxmox.ClusterResources{}
+// ResourceCacheStats contains the cluster resource cache statistics.
+type ResourceCacheStats struct {
+	// Hits is the number of lookups served from the cache.
+	Hits uint64
+	// Misses is the number of lookups not found in the cache.
+	Misses uint64
+	// Coalesced is the number of missed lookups which waited for a request already in flight.
+	Coalesced uint64
+}
 
-	if v, ok := c.resources.Get(name); ok {
-		resources, _ = v.(proxmox.ClusterResources)
+type resourceCacheStats struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	coalesced atomic.Uint64
+}
+
+// ResourceCacheStats returns the cluster resource cache statistics.
+func (c *APIClient) ResourceCacheStats() ResourceCacheStats {
+	return ResourceCacheStats{
+		Hits:      c.resourceStats.hits.Load(),
+		Misses:    c.resourceStats.misses.Load(),
+		Coalesced: c.resourceStats.coalesced.Load(),
+	}
+}
+
+// getResources returns the cluster resources of the type from the informer store or the cache.
+func (c *APIClient) getResources(ctx context.Context, name string) (_ proxmox.ClusterResources, err error) {
+	ctx, span := c.startSpan(ctx, "resources.get", attribute.String("proxmox.resource.type", name))
+	defer func() { endSpan(span, err) }()
+
+	if inf := c.informer.Load(); inf != nil {
+		if resources, ok := inf.store.list(name); ok {
+			c.resourceStats.hits.Add(1)
+			span.SetAttributes(attribute.String("proxmox.resource.source", "informer"))
+
+			return resources, nil
+		}
 	}
 
-	ttl := cache.DefaultExpiration
+	if v, ok := c.resources.Get(name); ok {
+		if resources, _ := v.(proxmox.ClusterResources); len(resources) > 0 {
+			c.resourceStats.hits.Add(1)
+			span.SetAttributes(attribute.String("proxmox.resource.source", "cache"))
 
-	switch name {
-	case "node":
-		ttl = time.Second * 5
-	case "vm":
-		ttl = time.Second * 5
-	case "storage":
-		ttl = time.Minute
+			return resources, nil
+		}
 	}
 
-	if len(resources) == 0 {
-		if err := c.Get(ctx, fmt.Sprintf("/cluster/resources?type=%s", name), &resources); err != nil {
+	c.resourceStats.misses.Add(1)
+	span.SetAttributes(attribute.String("proxmox.resource.source", "api"))
+
+	return c.fetchResources(ctx, name)
+}
+
+// fetchResources lists the cluster resources of the type and updates the cache and the informer store.
+// Concurrent calls for the same type share one API request.
+func (c *APIClient) fetchResources(ctx context.Context, name string) (proxmox.ClusterResources, error) {
+	leader := false
+
+	// The request is shared with other callers, so it must not be canceled with the context of the first one.
+	ch := c.resourceGroup.DoChan(name, func() (any, error) {
+		leader = true
+		gen := c.resourceGeneration(name)
+		resources := proxmox.ClusterResources{}
+
+		if err := c.Get(context.WithoutCancel(ctx), fmt.Sprintf("/cluster/resources?type=%s", name), &resources); err != nil {
 			return nil, fmt.Errorf("could not list cluster resources: %w", err)
 		}
 
-		c.resources.Set(name, resources, ttl)
+		// Do not cache the response if the resources were flushed while the request was in flight.
+		c.resourceMu.Lock()
+		if gen == c.resourceGen[name] {
+			c.resources.Set(name, resources, c.resourceTTL[name])
+
+			if inf := c.informer.Load(); inf != nil {
+				inf.update(name, resources)
+			}
+		}
+		c.resourceMu.Unlock()
+
+		return resources, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if !leader {
+			c.resourceStats.coalesced.Add(1)
+		}
+
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return res.Val.(proxmox.ClusterResources), nil
 	}
+}
+
+func (c *APIClient) resourceGeneration(name string) uint64 {
+	c.resourceMu.Lock()
+	defer c.resourceMu.Unlock()
 
-	return resources, nil
+	return c.resourceGen[name]
 }
@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetResources_Coalesce(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+	srv.Latency = 50 * time.Millisecond
+
+	var wg sync.WaitGroup
+
+	for range 20 {
+		wg.Go(func() {
+			_, err := client.GetVMByID(context.Background(), 100)
+			assert.NoError(t, err)
+		})
+	}
+
+	wg.Wait()
+
+	stats := client.ResourceCacheStats()
+	assert.Equal(t, 1, srv.RequestCount(http.MethodGet, "/cluster/resources"))
+	assert.Equal(t, uint64(20), stats.Hits+stats.Misses)
+	assert.Equal(t, stats.Misses-1, stats.Coalesced)
+}
+
+func TestGetResources_Canceled(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.Latency = 100 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.GetVMByID(ctx, 100)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	vms, err := client.GetVMTemplatesByFilter(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, vms, 1)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luthermonson/go-proxmox"
+)
+
+// GetCTByID returns an LXC container cluster resource by its ID.
+func (c *APIClient) GetCTByID(ctx context.Context, vmID uint64) (*proxmox.ClusterResource, error) {
+	cts, err := c.GetCTsByFilter(ctx, func(r *proxmox.ClusterResource) (bool, error) {
+		return r.VMID == vmID, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cts[0], nil
+}
+
+// GetCTsByFilter returns the LXC container cluster resources matching all the provided filter functions.
+// Use Or to match any of them.
+func (c *APIClient) GetCTsByFilter(ctx context.Context, filter ...ResourceFilter) (proxmox.ClusterResources, error) {
+	vmr, err := c.getResources(ctx, "vm")
+	if err != nil {
+		return nil, err
+	}
+
+	cts, err := filterItems(vmr, append([]ResourceFilter{isCT}, filter...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cts) > 0 {
+		return cts, nil
+	}
+
+	return nil, ErrContainerNotFound
+}
+
+// GetCTTemplateByID returns an LXC container template cluster resource by its ID.
+func (c *APIClient) GetCTTemplateByID(ctx context.Context, vmID uint64) (*proxmox.ClusterResource, error) {
+	cts, err := c.GetCTTemplatesByFilter(ctx, func(r *proxmox.ClusterResource) (bool, error) {
+		return r.VMID == vmID, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cts[0], nil
+}
+
+// GetCTTemplatesByFilter returns the LXC container template cluster resources matching all the provided filter functions.
+// Use Or to match any of them.
+func (c *APIClient) GetCTTemplatesByFilter(ctx context.Context, filter ...ResourceFilter) (proxmox.ClusterResources, error) {
+	vmr, err := c.getResources(ctx, "vm")
+	if err != nil {
+		return nil, err
+	}
+
+	cts, err := filterItems(vmr, append([]ResourceFilter{isCTTemplate}, filter...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cts) > 0 {
+		return cts, nil
+	}
+
+	return nil, ErrContainerTemplateNotFound
+}
+
+// GetCTConfig retrieves the status and the configuration of an LXC container by its ID.
+func (c *APIClient) GetCTConfig(ctx context.Context, vmID int) (*proxmox.Container, error) {
+	vmr, err := c.GetCTByID(ctx, uint64(vmID))
+	if err != nil {
+		return nil, err
+	}
+
+	if vmr.Status == "unknown" {
+		return nil, ErrVirtualMachineUnreachable
+	}
+
+	return c.getContainer(ctx, vmr.Node, vmID)
+}
+
+// getContainer returns the status and the configuration of the container on the node.
+func (c *APIClient) getContainer(ctx context.Context, nodeName string, vmID int) (*proxmox.Container, error) {
+	node, err := c.Client.Node(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := node.Container(ctx, vmID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find container with id %d: %w", vmID, err)
+	}
+
+	return ct, nil
+}
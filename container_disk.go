@@ -0,0 +1,134 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/luthermonson/go-proxmox"
+)
+
+// AttachCTMountPoint attaches a volume to the LXC container as the mount point mp (mp0, mp1, ...).
+// A volume like "local-lvm:8" allocates a new 8 GiB volume on the storage.
+func (c *APIClient) AttachCTMountPoint(ctx context.Context, vmID int, mp string, mount CTMountPoint) (err error) {
+	ctx, span := c.startSpan(ctx, "AttachCTMountPoint", AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	ctr, err := c.GetCTByID(ctx, uint64(vmID))
+	if err != nil {
+		return err
+	}
+
+	value, err := mount.ToString()
+	if err != nil {
+		return fmt.Errorf("unable to encode mount point %s: %w", mp, err)
+	}
+
+	// A bind mount point has no storage.
+	storages := []string{}
+	if storage, _, ok := strings.Cut(mount.Volume, ":"); ok && !strings.HasPrefix(mount.Volume, "/") {
+		storages = append(storages, storage)
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{ctr.Node}, c.storageSlots(ctx, ctr.Node, storages))
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := c.updateCTConfig(ctx, ctr.Node, vmID, map[string]any{mp: value}); err != nil {
+		return fmt.Errorf("unable to attach mount point: %w, options=%s=%s", err, mp, value)
+	}
+
+	return nil
+}
+
+// DetachCTMountPoint detaches the mount point from the LXC container.
+// The volume is kept as an unused disk of the container.
+func (c *APIClient) DetachCTMountPoint(ctx context.Context, vmID int, mp string) (err error) {
+	ctx, span := c.startSpan(ctx, "DetachCTMountPoint", AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	ctr, err := c.GetCTByID(ctx, uint64(vmID))
+	if err != nil {
+		return err
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{ctr.Node}, nil)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := c.updateCTConfig(ctx, ctr.Node, vmID, map[string]any{"delete": mp}); err != nil {
+		return fmt.Errorf("failed to detach mount point %s: %w", mp, err)
+	}
+
+	return nil
+}
+
+// ResizeCTDisk resizes the root filesystem or a mount point of the LXC container.
+func (c *APIClient) ResizeCTDisk(ctx context.Context, vmID int, node, disk, size string) (err error) {
+	ctx, span := c.startSpan(ctx, "ResizeCTDisk", AttrNode.String(node), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.ResizeCTDiskAsync(ctx, vmID, node, disk, size)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// ResizeCTDiskAsync resizes the root filesystem or a mount point of the LXC container and returns the handle of the resize task.
+func (c *APIClient) ResizeCTDiskAsync(ctx context.Context, vmID int, node, disk, size string) (_ *TaskHandle, err error) {
+	if _, err := c.getContainer(ctx, node, vmID); err != nil {
+		return nil, err
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{node}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newDiskTaskHandle(OperationDiskResize, vmID, "unable to resize container disk", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		return c.resizeCTDisk(ctx, node, vmID, disk, size)
+	}); err != nil {
+		return nil, fmt.Errorf("unable to resize container disk: %w", err)
+	}
+
+	return h, nil
+}
+
+func (c *APIClient) resizeCTDisk(ctx context.Context, node string, vmID int, disk, size string) (*proxmox.Task, error) {
+	var upid proxmox.UPID
+	if err := c.Client.Put(ctx, fmt.Sprintf("/nodes/%s/lxc/%d/resize", node, vmID), map[string]any{"disk": disk, "size": size}, &upid); err != nil {
+		return nil, err
+	}
+
+	return proxmox.NewTask(upid, c.Client), nil
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/luthermonson/go-proxmox"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// StartCTByID starts an LXC container by its ID.
+func (c *APIClient) StartCTByID(ctx context.Context, nodeName string, vmID int) (_ *proxmox.Container, err error) {
+	ctx, span := c.startSpan(ctx, "StartCTByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.StartCTByIDAsync(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	if err = h.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.getContainer(ctx, nodeName, vmID)
+}
+
+// StartCTByIDAsync starts an LXC container by its ID and returns the handle of the start task.
+func (c *APIClient) StartCTByIDAsync(ctx context.Context, nodeName string, vmID int) (_ *TaskHandle, err error) {
+	ct, err := c.getContainer(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{nodeName}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationStart, vmID, "unable to start container", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	if err = h.startTask(ctx, ct.Start); err != nil {
+		return nil, fmt.Errorf("failed to start container %d: %w", vmID, err)
+	}
+
+	return h, nil
+}
+
+// DeleteCTByID deletes an LXC container by its ID.
+// If the container is running it is stopped first.
+func (c *APIClient) DeleteCTByID(ctx context.Context, nodeName string, vmID int) (err error) {
+	ctx, span := c.startSpan(ctx, "DeleteCTByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.DeleteCTByIDAsync(ctx, nodeName, vmID)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// DeleteCTByIDAsync deletes an LXC container by its ID and returns the task handle.
+// If the container is running, the handle tracks the stop task and Wait deletes the container after it.
+// Other running tasks of the container are handled according to WithTaskConflictPolicy.
+func (c *APIClient) DeleteCTByIDAsync(ctx context.Context, nodeName string, vmID int) (_ *TaskHandle, err error) {
+	if err := c.checkTaskConflicts(ctx, nodeName, vmID, OperationDelete); err != nil {
+		return nil, err
+	}
+
+	ct, err := c.getContainer(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{nodeName}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationDelete, vmID, fmt.Sprintf("unable to delete container %d", vmID), release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	if ct.Status == "running" {
+		if err = h.startTask(ctx, ct.Stop); err != nil {
+			return nil, fmt.Errorf("failed to stop container %d: %w", vmID, err)
+		}
+
+		h.op = OperationStop
+		h.desc = fmt.Sprintf("unable to stop container %d", vmID)
+		h.then = func(ctx context.Context) error {
+			if err := c.runLocked(ctx, OperationDelete, ct.Delete); err != nil {
+				return fmt.Errorf("unable to delete container %d: %w", vmID, err)
+			}
+
+			c.lastVMID.Set(strconv.Itoa(vmID), struct{}{}, c.vmidTTL)
+
+			return nil
+		}
+
+		return h, nil
+	}
+
+	if err = h.startTask(ctx, ct.Delete); err != nil {
+		return nil, fmt.Errorf("cannot delete container with id %d: %w", vmID, err)
+	}
+
+	h.then = func(context.Context) error {
+		c.lastVMID.Set(strconv.Itoa(vmID), struct{}{}, c.vmidTTL)
+
+		return nil
+	}
+
+	return h, nil
+}
+
+// MigrateCTByID migrates an LXC container to another node by its ID.
+// A running container can be migrated only in the restart mode, it is stopped
+// on the source node and started again on the target node.
+func (c *APIClient) MigrateCTByID(ctx context.Context, vmID int, dstNode string, restart bool) (err error) {
+	ctx, span := c.startSpan(ctx, "MigrateCTByID", AttrVMID.Int(vmID), attribute.String("proxmox.target_node", dstNode))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.MigrateCTByIDAsync(ctx, vmID, dstNode, restart)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// MigrateCTByIDAsync migrates an LXC container to another node by its ID and returns the handle of the migration task.
+func (c *APIClient) MigrateCTByIDAsync(ctx context.Context, vmID int, dstNode string, restart bool) (_ *TaskHandle, err error) {
+	ctr, err := c.GetCTByID(ctx, uint64(vmID))
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{ctr.Node, dstNode}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationMigrate, vmID, "unable to migrate container", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	params := &proxmox.ContainerMigrateOptions{
+		Target:  dstNode,
+		Restart: proxmox.IntOrBool(restart),
+	}
+
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/lxc/%d/migrate", ctr.Node, ctr.VMID), params, &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// CloneCT clones an LXC container template to create a new container with the specified options.
+func (c *APIClient) CloneCT(ctx context.Context, templateID int, options CTCloneRequest) (_ int, err error) {
+	ctx, span := c.startSpan(ctx, "CloneCT", AttrNode.String(options.Node), attribute.Int("proxmox.template_vmid", templateID), AttrStorage.String(options.Storage))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.CloneCTAsync(ctx, templateID, options)
+	if err != nil {
+		var cloneErr *CloneError
+		if errors.As(err, &cloneErr) {
+			return cloneErr.VMID, err
+		}
+
+		return 0, err
+	}
+	defer h.Close()
+
+	span.SetAttributes(AttrVMID.Int(h.VMID()))
+
+	return h.VMID(), h.Wait(ctx)
+}
+
+// CloneCTAsync clones an LXC container template and returns the handle of the clone task.
+// Wait applies the instance options of the request to the new container.
+//
+// If the clone request fails after the container ID was allocated, the error is a *CloneError
+// carrying the ID so the caller can clean up.
+func (c *APIClient) CloneCTAsync(ctx context.Context, templateID int, options CTCloneRequest) (_ *TaskHandle, err error) {
+	ctTemplate, err := c.getContainer(ctx, options.Node, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	storages := []string{options.Storage}
+	if options.Storage == "" {
+		storages = getCTStorages(ctTemplate.ContainerConfig)
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{options.Node}, c.storageSlots(ctx, options.Node, storages))
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationClone, options.NewID, "unable to clone container", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	ctCloneOptions := proxmox.ContainerCloneOptions{
+		NewID:       options.NewID,
+		Description: options.Description,
+		Full:        options.Full,
+		Hostname:    options.Hostname,
+		Pool:        options.Pool,
+		Storage:     options.Storage,
+	}
+
+	newid, task, err := ctTemplate.Clone(ctx, &ctCloneOptions)
+	if err != nil {
+		err = fmt.Errorf("failed to clone container template %d: %w", templateID, err)
+		if newid != 0 {
+			err = &CloneError{VMID: newid, Err: err}
+		}
+
+		return nil, err
+	}
+
+	h.vmid = newid
+	h.setTask(task)
+	h.then = func(ctx context.Context) error {
+		return c.configureClonedCT(ctx, options, newid)
+	}
+
+	return h, nil
+}
+
+// configureClonedCT applies the instance options of the clone request to the new container.
+func (c *APIClient) configureClonedCT(ctx context.Context, options CTCloneRequest, newid int) error {
+	if options.DiskSize != "" {
+		resizeCtx, resizeSpan := c.startSpan(ctx, "ct.resizeDisk", AttrVMID.Int(newid), attribute.String("proxmox.disk", "rootfs"))
+		err := c.runLocked(resizeCtx, OperationDiskResize, func(ctx context.Context) (*proxmox.Task, error) {
+			return c.resizeCTDisk(ctx, options.Node, newid, "rootfs", options.DiskSize)
+		})
+		endSpan(resizeSpan, err)
+
+		if err != nil {
+			return fmt.Errorf("failed to resize disk rootfs for container %d: %w", newid, err)
+		}
+	}
+
+	params := map[string]any{}
+
+	if options.CPU > 0 {
+		params["cores"] = options.CPU
+	}
+
+	if options.Memory > 0 {
+		params["memory"] = options.Memory
+	}
+
+	if options.Tags != "" {
+		params["tags"] = options.Tags
+	}
+
+	if len(params) > 0 {
+		if err := c.updateCTConfig(ctx, options.Node, newid, params); err != nil {
+			return fmt.Errorf("unable to configure container %d: %w", newid, err)
+		}
+	}
+
+	return nil
+}
+
+// updateCTConfig updates the container configuration, retrying while the container is locked.
+// Unlike qemu, the config update of a container is synchronous and does not start a task.
+func (c *APIClient) updateCTConfig(ctx context.Context, nodeName string, vmID int, params map[string]any) error {
+	return c.runLocked(ctx, OperationConfig, func(ctx context.Context) (*proxmox.Task, error) {
+		return nil, c.Client.Put(ctx, fmt.Sprintf("/nodes/%s/lxc/%d/config", nodeName, vmID), params, nil)
+	})
+}
+
+// getCTStorages returns the storages of the root filesystem and the mount points of the container.
+func getCTStorages(cfg *proxmox.ContainerConfig) []string {
+	if cfg == nil {
+		return nil
+	}
+
+	storages := []string{}
+
+	for _, volume := range append(slices.Collect(maps.Values(cfg.MergeMps())), cfg.RootFS) {
+		if storage, _, ok := strings.Cut(volume, ":"); ok && !strings.HasPrefix(volume, "/") && !slices.Contains(storages, storage) {
+			storages = append(storages, storage)
+		}
+	}
+
+	slices.Sort(storages)
+
+	return storages
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestContainerLifecycle(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddContainerTemplate("pve-1", 8000, map[string]any{
+		"hostname": "debian",
+		"cores":    1,
+		"memory":   512,
+		"rootfs":   "local-lvm:base-8000-disk-0,size=4G",
+		"net0":     "name=eth0,bridge=vmbr0,ip=dhcp",
+	})
+
+	_, err := client.GetVMByID(ctx, 8000)
+	assert.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+
+	tmpl, err := client.GetCTTemplateByID(ctx, 8000)
+	require.NoError(t, err)
+	assert.Equal(t, "pve-1", tmpl.Node)
+
+	vmid, err := client.CloneCT(ctx, 8000, goproxmox.CTCloneRequest{
+		Node:     "pve-1",
+		NewID:    200,
+		Hostname: "web-1",
+		Full:     1,
+		CPU:      2,
+		Memory:   1024,
+		DiskSize: "8G",
+		Tags:     "web",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 200, vmid)
+
+	ct, err := client.GetCTConfig(ctx, 200)
+	require.NoError(t, err)
+	assert.Equal(t, "web-1", ct.ContainerConfig.Hostname)
+	assert.Equal(t, 2, ct.ContainerConfig.Cores)
+	assert.Equal(t, 1024, ct.ContainerConfig.Memory)
+	assert.Equal(t, "local-lvm:vm-200-disk-0,size=8G", ct.ContainerConfig.RootFS)
+
+	ct, err = client.StartCTByID(ctx, "pve-1", 200)
+	require.NoError(t, err)
+	assert.Equal(t, "running", ct.Status)
+
+	err = client.MigrateCTByID(ctx, 200, "pve-2", false)
+	assert.Error(t, err)
+
+	require.NoError(t, client.MigrateCTByID(ctx, 200, "pve-2", true))
+
+	ctr, err := client.GetCTByID(ctx, 200)
+	require.NoError(t, err)
+	assert.Equal(t, "pve-2", ctr.Node)
+	assert.Equal(t, []string{"local-lvm:vm-200-disk-0"}, srv.Volumes("pve-2", "local-lvm"))
+
+	require.NoError(t, client.DeleteCTByID(ctx, "pve-2", 200))
+
+	_, err = client.GetCTByID(ctx, 200)
+	assert.ErrorIs(t, err, goproxmox.ErrContainerNotFound)
+	assert.Empty(t, srv.Volumes("pve-2", "local-lvm"))
+
+	_, err = client.StartCTByID(ctx, "pve-1", 200)
+	assert.ErrorIs(t, err, goproxmox.ErrContainerNotFound)
+}
+
+func TestContainerMountPoints(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddContainer("pve-1", 200, map[string]any{
+		"hostname": "web-1",
+		"rootfs":   "rbd:vm-200-disk-0,size=4G",
+	})
+
+	require.NoError(t, client.AttachCTMountPoint(ctx, 200, "mp0", goproxmox.CTMountPoint{
+		Volume:     "local-lvm:1",
+		MountPoint: "/data",
+		Backup:     goproxmox.NewIntOrBool(true),
+	}))
+	require.NoError(t, client.ResizeCTDisk(ctx, 200, "pve-1", "mp0", "+1G"))
+
+	ct, err := client.GetCTConfig(ctx, 200)
+	require.NoError(t, err)
+
+	mp := goproxmox.CTMountPoint{}
+	require.NoError(t, mp.UnmarshalString(ct.ContainerConfig.Mp0))
+	assert.Equal(t, goproxmox.CTMountPoint{
+		Volume:     "local-lvm:vm-200-disk-0",
+		MountPoint: "/data",
+		Backup:     goproxmox.NewIntOrBool(true),
+		Size:       "2G",
+	}, mp)
+
+	require.NoError(t, client.DetachCTMountPoint(ctx, 200, "mp0"))
+
+	vm, ok := srv.VM(200)
+	require.True(t, ok)
+	assert.NotContains(t, vm.Config, "mp0")
+	assert.Equal(t, "local-lvm:vm-200-disk-0", vm.Config["unused0"])
+}
@@ -16,11 +16,23 @@ limitations under the License.
 
 package goproxmox
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/luthermonson/go-proxmox"
+	"github.com/pkg/errors"
+)
 
 var (
 	// ErrNodeNotFound is returned when a node is not found.
 	ErrNodeNotFound = errors.New("node not found")
+	// ErrNodeOffline is returned when the node serving the request is offline or unreachable.
+	ErrNodeOffline = errors.New("node offline")
 
 	// ErrVirtualMachineNotFound is returned when a virtual machine is not found.
 	ErrVirtualMachineNotFound = errors.New("VM machine not found")
@@ -28,7 +40,278 @@ var (
 	ErrVirtualMachineTemplateNotFound = errors.New("VM template not found")
 	// ErrVirtualMachineUnreachable is returned when a virtual machine is unreachable. And it has unknown status.
 	ErrVirtualMachineUnreachable = errors.New("VM machine unreachable")
+	// ErrUnexpectedStatus is returned when a virtual machine is not in the expected state after a power operation.
+	ErrUnexpectedStatus = errors.New("unexpected VM status")
+
+	// ErrContainerNotFound is returned when a container is not found.
+	ErrContainerNotFound = errors.New("container not found")
+	// ErrContainerTemplateNotFound is returned when a container template is not found.
+	ErrContainerTemplateNotFound = errors.New("container template not found")
 
 	// ErrNotFound is returned when a resource is not found.
 	ErrNotFound = errors.New("not found")
+
+	// ErrBadRequest is returned when the request parameters are rejected by the API.
+	ErrBadRequest = errors.New("bad request")
+	// ErrPermissionDenied is returned when the API user has no permission for the request.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrLocked is returned when the resource is locked by another operation.
+	ErrLocked = errors.New("resource locked")
+	// ErrConflict is returned when the resource already exists or was modified concurrently.
+	ErrConflict = errors.New("conflict")
+
+	// ErrNoFreeVMID is returned when no free VM ID is found in the allowed ranges.
+	ErrNoFreeVMID = errors.New("no free VM ID")
+
+	// ErrLimitExceeded is returned in the fail fast mode when the request rate or the task concurrency limit is reached.
+	ErrLimitExceeded = errors.New("client limit exceeded")
+	// ErrTaskConflict is returned when the VM has running tasks and the client is configured to reject the operation.
+	ErrTaskConflict = errors.New("conflicting task running")
+	// ErrMigrationBlocked is returned when the migration pre-flight check finds reasons the VM can't migrate.
+	ErrMigrationBlocked = errors.New("migration blocked")
 )
+
+// APIError is returned when the Proxmox API responds with an error status code.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Method is the HTTP method of the request.
+	Method string
+	// Path is the API path of the request, without the /api2/json prefix.
+	Path string
+	// Message is the error message returned by the API.
+	Message string
+	// Errors contains the per parameter errors of a 400 response.
+	Errors map[string]string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+
+	if len(e.Errors) > 0 {
+		keys := make([]string, 0, len(e.Errors))
+		for k := range e.Errors {
+			keys = append(keys, k)
+		}
+
+		slices.Sort(keys)
+
+		details := make([]string, 0, len(keys))
+		for _, k := range keys {
+			details = append(details, fmt.Sprintf("%s: %s", k, strings.TrimSpace(e.Errors[k])))
+		}
+
+		msg += " (" + strings.Join(details, ", ") + ")"
+	}
+
+	return msg
+}
+
+// Is classifies the error, so it can be checked with errors.Is against the package sentinel errors.
+// The 401 and 403 errors also match proxmox.ErrNotAuthorized, which go-proxmox checks for optional requests.
+func (e *APIError) Is(target error) bool {
+	msg := strings.ToLower(e.Message)
+	for _, v := range e.Errors {
+		msg += " " + strings.ToLower(v)
+	}
+
+	switch target { //nolint:errorlint
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrPermissionDenied:
+		return e.StatusCode == http.StatusForbidden
+	case proxmox.ErrNotAuthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound ||
+			strings.Contains(msg, "does not exist") ||
+			strings.Contains(msg, "no such")
+	case ErrVirtualMachineNotFound:
+		return strings.Contains(msg, "qemu-server/") && strings.Contains(msg, "does not exist")
+	case ErrContainerNotFound:
+		return strings.Contains(msg, "lxc/") && strings.Contains(msg, "does not exist")
+	case ErrNodeNotFound:
+		return strings.Contains(msg, "hostname lookup")
+	case ErrNodeOffline:
+		return e.StatusCode == 595 || e.StatusCode == 596 ||
+			strings.Contains(msg, "no route to host") ||
+			strings.Contains(msg, "node is offline")
+	case ErrLocked:
+		return strings.Contains(msg, "can't lock file") ||
+			strings.Contains(msg, "is locked")
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict ||
+			strings.Contains(msg, "already exists") ||
+			strings.Contains(msg, "file changed by other user")
+	}
+
+	return false
+}
+
+// proxmoxErrorRegexp matches the errors go-proxmox returns for the API error responses,
+// e.g. "bad request: 400 Parameter verification failed. - {...}" or "500 VM is locked (backup)".
+var proxmoxErrorRegexp = regexp.MustCompile(`^(?:bad request: )?([1-5]\d\d) (.*)$`)
+
+// errorIs reports whether the error matches the target like errors.Is.
+// An HTTP client set with proxmox.WithHTTPClient bypasses the transport which returns APIError,
+// so the error text of go-proxmox is classified the same way as a fallback.
+func errorIs(err, target error) bool {
+	if errors.Is(err, target) {
+		return true
+	}
+
+	var apiErr *APIError
+	if err == nil || errors.As(err, &apiErr) {
+		return false
+	}
+
+	for inner := errors.Unwrap(err); inner != nil; inner = errors.Unwrap(inner) {
+		err = inner
+	}
+
+	m := proxmoxErrorRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return false
+	}
+
+	code, _ := strconv.Atoi(m[1]) //nolint:errcheck
+
+	return (&APIError{StatusCode: code, Message: m[2]}).Is(target)
+}
+
+// LimitError is returned in the fail fast mode when a client limit is reached.
+type LimitError struct {
+	// Scope is the limit which was reached: "rate", "node" or "storage".
+	Scope string
+	// Name is the node or the storage name, "node/storage" for a node local storage.
+	Name string
+}
+
+// Error implements the error interface.
+func (e *LimitError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s limit exceeded", e.Scope)
+	}
+
+	return fmt.Sprintf("%s %s: concurrent task limit exceeded", e.Scope, e.Name)
+}
+
+// Is reports whether the error matches ErrLimitExceeded.
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded //nolint:errorlint
+}
+
+// TaskConflictError is returned when the operation is rejected because of the running tasks of the VM.
+type TaskConflictError struct {
+	// VMID is the ID of the VM.
+	VMID int
+	// Tasks contains the IDs of the running tasks.
+	Tasks []string
+}
+
+// Error implements the error interface.
+func (e *TaskConflictError) Error() string {
+	return fmt.Sprintf("vm %d has running tasks: %s", e.VMID, strings.Join(e.Tasks, ", "))
+}
+
+// Is reports whether the error matches ErrTaskConflict.
+func (e *TaskConflictError) Is(target error) bool {
+	return target == ErrTaskConflict //nolint:errorlint
+}
+
+// CloneError is returned when the clone request fails after the ID of the new VM or container was allocated.
+type CloneError struct {
+	// VMID is the ID allocated for the clone, the caller may have to clean it up.
+	VMID int
+	// Err is the error of the clone request.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *CloneError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the clone request.
+func (e *CloneError) Unwrap() error {
+	return e.Err
+}
+
+// TaskCancelResult is the outcome of stopping a task after the context was done.
+type TaskCancelResult string
+
+const (
+	// TaskStopped means the task was stopped.
+	TaskStopped TaskCancelResult = "stopped"
+	// TaskFinished means the task completed before it could be stopped.
+	// The failure of a task which completed with an error is in TaskCanceledError.TaskErr.
+	TaskFinished TaskCancelResult = "finished"
+	// TaskStopFailed means the task could not be stopped and may still be running.
+	TaskStopFailed TaskCancelResult = "stop failed"
+)
+
+// TaskCanceledError is returned when the context is done while waiting for a task
+// and the client is configured to stop abandoned tasks.
+type TaskCanceledError struct {
+	// UPID is the ID of the task.
+	UPID string
+	// Result is the outcome of the stop request.
+	Result TaskCancelResult
+	// Err is the context error which interrupted the wait.
+	Err error
+	// StopErr is the error of the stop request, for TaskStopFailed.
+	StopErr error
+	// TaskErr is the *TaskFailedError of a task which failed on its own, for TaskFinished.
+	TaskErr error
+}
+
+// Error implements the error interface.
+func (e *TaskCanceledError) Error() string {
+	switch e.Result {
+	case TaskStopped:
+		return fmt.Sprintf("task %s stopped: %v", e.UPID, e.Err)
+	case TaskFinished:
+		if e.TaskErr != nil {
+			return fmt.Sprintf("task %s failed before it could be stopped: %v: %v", e.UPID, e.Err, e.TaskErr)
+		}
+
+		return fmt.Sprintf("task %s finished before it could be stopped: %v", e.UPID, e.Err)
+	default:
+		return fmt.Sprintf("task %s could not be stopped: %v: %v", e.UPID, e.Err, e.StopErr)
+	}
+}
+
+// Unwrap returns the context error, the stop error and the task failure.
+func (e *TaskCanceledError) Unwrap() []error {
+	return []error{e.Err, e.StopErr, e.TaskErr}
+}
+
+// TaskFailedError is returned when a Proxmox task completes with an error exit status.
+type TaskFailedError struct {
+	// UPID is the ID of the task.
+	UPID string
+	// Type is the task type, e.g. qmclone.
+	Type string
+	// ExitStatus is the exit status of the task.
+	ExitStatus string
+	// Log contains the last lines of the task log.
+	Log []string
+}
+
+// Error implements the error interface.
+func (e *TaskFailedError) Error() string {
+	if len(e.Log) == 0 {
+		return e.ExitStatus
+	}
+
+	return fmt.Sprintf("%s, last log lines: %s", e.ExitStatus, strings.Join(e.Log, " | "))
+}
+
+// Is reports whether the task failed because the VM was locked, so it can be checked against ErrLocked.
+func (e *TaskFailedError) Is(target error) bool {
+	msg := strings.ToLower(e.ExitStatus)
+
+	return target == ErrLocked && //nolint:errorlint
+		(strings.Contains(msg, "can't lock file") || strings.Contains(msg, "is locked"))
+}
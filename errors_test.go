@@ -0,0 +1,155 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/luthermonson/go-proxmox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+	"github.com/sergelogvinov/go-proxmox/goproxmoxtest"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		err    *goproxmox.APIError
+		target error
+	}{
+		{
+			name:   "bad-request",
+			err:    &goproxmox.APIError{StatusCode: http.StatusBadRequest, Message: "Parameter verification failed."},
+			target: goproxmox.ErrBadRequest,
+		},
+		{
+			name:   "permission-denied",
+			err:    &goproxmox.APIError{StatusCode: http.StatusForbidden, Message: "Permission check failed (/vms/100, VM.Config.Disk)"},
+			target: goproxmox.ErrPermissionDenied,
+		},
+		{
+			name:   "not-authorized",
+			err:    &goproxmox.APIError{StatusCode: http.StatusForbidden, Message: "Permission check failed (/, Sys.Audit)"},
+			target: proxmox.ErrNotAuthorized,
+		},
+		{
+			name:   "vm-not-found",
+			err:    &goproxmox.APIError{StatusCode: http.StatusInternalServerError, Message: "Configuration file 'nodes/pve-1/qemu-server/100.conf' does not exist"},
+			target: goproxmox.ErrVirtualMachineNotFound,
+		},
+		{
+			name:   "ct-not-found",
+			err:    &goproxmox.APIError{StatusCode: http.StatusInternalServerError, Message: "Configuration file 'nodes/pve-1/lxc/200.conf' does not exist"},
+			target: goproxmox.ErrContainerNotFound,
+		},
+		{
+			name:   "lock-timeout",
+			err:    &goproxmox.APIError{StatusCode: http.StatusInternalServerError, Message: "can't lock file '/var/lock/qemu-server/lock-100.conf' - got timeout"},
+			target: goproxmox.ErrLocked,
+		},
+		{
+			name:   "node-offline",
+			err:    &goproxmox.APIError{StatusCode: 595, Message: "No route to host"},
+			target: goproxmox.ErrNodeOffline,
+		},
+		{
+			name:   "conflict",
+			err:    &goproxmox.APIError{StatusCode: http.StatusBadRequest, Errors: map[string]string{"vmid": "VM 100 already exists"}},
+			target: goproxmox.ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, tt.err, tt.target)
+		})
+	}
+}
+
+func TestAPIError_Client(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "lock": "backup"})
+	srv.AddVM("pve-2", 101, map[string]any{"name": "worker-2"})
+	srv.SetNodeStatus("pve-2", "offline")
+
+	err := client.UpdateVMByID(ctx, "pve-1", 100, map[string]interface{}{"name": "worker-3"})
+	assert.ErrorIs(t, err, goproxmox.ErrLocked)
+
+	var apiErr *goproxmox.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, http.MethodPost, apiErr.Method)
+	assert.Equal(t, "/nodes/pve-1/qemu/100/config", apiErr.Path)
+	assert.Equal(t, "VM is locked (backup)", apiErr.Message)
+
+	_, err = client.StartVMByID(ctx, "pve-1", 102)
+	assert.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+
+	_, err = client.StartVMByID(ctx, "pve-2", 101)
+	assert.ErrorIs(t, err, goproxmox.ErrNodeOffline)
+}
+
+func TestGetNextID(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	id, err := client.GetNextID(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 101, id)
+}
+
+func TestAPIError_APITokenWithoutSysAudit(t *testing.T) {
+	t.Parallel()
+
+	srv := goproxmoxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	srv.AddNode("pve-1")
+	srv.AddContainer("pve-1", 200, map[string]any{"hostname": "web-1"})
+	srv.AddAPIToken("capmox@pve!capmox")
+
+	client, err := goproxmox.NewAPIClient(srv.URL(), proxmox.WithAPIToken("capmox@pve!capmox", "secret"))
+	require.NoError(t, err)
+
+	// go-proxmox checks the node status, which requires Sys.Audit, and ignores the permission error.
+	ct, err := client.GetCTConfig(context.Background(), 200)
+	require.NoError(t, err)
+	assert.Equal(t, "web-1", ct.ContainerConfig.Hostname)
+
+	_, err = client.Client.Node(context.Background(), "pve-1")
+	require.NoError(t, err)
+
+	node := &proxmox.Node{}
+	err = client.Get(context.Background(), "/nodes/pve-1/status", node)
+	assert.ErrorIs(t, err, goproxmox.ErrPermissionDenied)
+	assert.ErrorIs(t, err, proxmox.ErrNotAuthorized)
+}
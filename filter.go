@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/luthermonson/go-proxmox"
+)
+
+// ResourceFilter is a predicate on a cluster resource, used by the Get*ByFilter functions.
+// An error aborts the lookup.
+type ResourceFilter = func(*proxmox.ClusterResource) (bool, error)
+
+// And returns a filter which matches if all the filters match. It matches everything without filters.
+// The Get*ByFilter functions combine their filters with And.
+func And[T any](filters ...func(T) (bool, error)) func(T) (bool, error) {
+	return func(r T) (bool, error) {
+		for _, f := range filters {
+			ok, err := f(r)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+
+		return true, nil
+	}
+}
+
+// Or returns a filter which matches if any of the filters matches. It matches nothing without filters.
+func Or[T any](filters ...func(T) (bool, error)) func(T) (bool, error) {
+	return func(r T) (bool, error) {
+		for _, f := range filters {
+			ok, err := f(r)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// Not returns a filter which matches if the filter does not match.
+func Not[T any](filter func(T) (bool, error)) func(T) (bool, error) {
+	return func(r T) (bool, error) {
+		ok, err := filter(r)
+		if err != nil {
+			return false, err
+		}
+
+		return !ok, nil
+	}
+}
+
+// ByNode matches the resources on the node.
+func ByNode(node string) ResourceFilter {
+	return func(r *proxmox.ClusterResource) (bool, error) {
+		return r.Node == node, nil
+	}
+}
+
+// ByTag matches the resources with the tag.
+func ByTag(tag string) ResourceFilter {
+	return func(r *proxmox.ClusterResource) (bool, error) {
+		for _, t := range strings.FieldsFunc(r.Tags, isTagSeparator) {
+			if t == tag {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// ByPool matches the resources in the pool.
+func ByPool(pool string) ResourceFilter {
+	return func(r *proxmox.ClusterResource) (bool, error) {
+		return r.Pool == pool, nil
+	}
+}
+
+// ByStatus matches the resources with the status, e.g. running, stopped or online.
+func ByStatus(status string) ResourceFilter {
+	return func(r *proxmox.ClusterResource) (bool, error) {
+		return r.Status == status, nil
+	}
+}
+
+// ByNameRegexp matches the resources with the name matching the regular expression.
+// An invalid expression is returned as the filter error.
+func ByNameRegexp(expr string) ResourceFilter {
+	re, err := regexp.Compile(expr)
+
+	return func(r *proxmox.ClusterResource) (bool, error) {
+		if err != nil {
+			return false, fmt.Errorf("invalid name expression %q: %w", expr, err)
+		}
+
+		return re.MatchString(r.Name), nil
+	}
+}
+
+// filterItems returns the items matching all the filters, in their order.
+func filterItems[T any](items []T, filters ...func(T) (bool, error)) ([]T, error) {
+	match := And(filters...)
+	res := make([]T, 0, len(items))
+
+	for _, item := range items {
+		ok, err := match(item)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			res = append(res, item)
+		}
+	}
+
+	return res, nil
+}
+
+// isTagSeparator reports whether the rune separates the tags of a resource.
+func isTagSeparator(r rune) bool {
+	return r == ';' || r == ',' || r == ' '
+}
+
+// isVM matches the qemu VMs, which are not templates or placeholders of VMIDAllocator.
+func isVM(r *proxmox.ClusterResource) (bool, error) {
+	return r.Type == "qemu" && r.Template == 0 && !isVMIDPlaceholder(r), nil
+}
+
+// isVMTemplate matches the qemu VM templates.
+func isVMTemplate(r *proxmox.ClusterResource) (bool, error) {
+	return r.Type == "qemu" && r.Template != 0, nil
+}
+
+// isCT matches the LXC containers, which are not templates.
+func isCT(r *proxmox.ClusterResource) (bool, error) {
+	return r.Type == "lxc" && r.Template == 0, nil
+}
+
+// isCTTemplate matches the LXC container templates.
+func isCTTemplate(r *proxmox.ClusterResource) (bool, error) {
+	return r.Type == "lxc" && r.Template != 0, nil
+}
+
+// isStorage matches the storage resources.
+func isStorage(r *proxmox.ClusterResource) (bool, error) {
+	return r.Type == "storage", nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/luthermonson/go-proxmox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestResourceFilters(t *testing.T) {
+	t.Parallel()
+
+	vm := &proxmox.ClusterResource{Name: "worker-1", Node: "pve-1", Pool: "k8s", Status: "running", Tags: "k8s;worker"}
+	errFilter := func(*proxmox.ClusterResource) (bool, error) { return false, errors.New("filter error") }
+
+	tests := []struct {
+		name   string
+		filter goproxmox.ResourceFilter
+		match  bool
+		err    bool
+	}{
+		{name: "node", filter: goproxmox.ByNode("pve-1"), match: true},
+		{name: "other-node", filter: goproxmox.ByNode("pve-2")},
+		{name: "tag", filter: goproxmox.ByTag("worker"), match: true},
+		{name: "tag-prefix", filter: goproxmox.ByTag("work")},
+		{name: "pool", filter: goproxmox.ByPool("k8s"), match: true},
+		{name: "status", filter: goproxmox.ByStatus("stopped")},
+		{name: "name", filter: goproxmox.ByNameRegexp(`^worker-\d+$`), match: true},
+		{name: "invalid-name", filter: goproxmox.ByNameRegexp(`(`), err: true},
+		{name: "and", filter: goproxmox.And(goproxmox.ByNode("pve-1"), goproxmox.ByTag("worker")), match: true},
+		{name: "and-mismatch", filter: goproxmox.And(goproxmox.ByNode("pve-1"), goproxmox.ByTag("control-plane"))},
+		{name: "and-empty", filter: goproxmox.And[*proxmox.ClusterResource](), match: true},
+		{name: "or", filter: goproxmox.Or(goproxmox.ByNode("pve-2"), goproxmox.ByTag("worker")), match: true},
+		{name: "or-empty", filter: goproxmox.Or[*proxmox.ClusterResource]()},
+		{name: "not", filter: goproxmox.Not(goproxmox.ByStatus("stopped")), match: true},
+		{name: "and-error", filter: goproxmox.And(goproxmox.ByNode("pve-1"), errFilter), err: true},
+		{name: "or-short-circuit", filter: goproxmox.Or(goproxmox.ByNode("pve-1"), errFilter), match: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := tt.filter(vm)
+			if tt.err {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.match, ok)
+		})
+	}
+}
+
+func TestGetVMsByFilter(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "tags": "k8s;worker"})
+	srv.AddVM("pve-1", 101, map[string]any{"name": "control-plane-1", "tags": "k8s"})
+	srv.AddVM("pve-2", 102, map[string]any{"name": "worker-2", "tags": "k8s;worker"})
+
+	vms, err := client.GetVMsByFilter(ctx, goproxmox.ByNode("pve-1"), goproxmox.ByTag("k8s"))
+	require.NoError(t, err)
+	require.Len(t, vms, 2)
+	assert.Equal(t, uint64(100), vms[0].VMID)
+	assert.Equal(t, uint64(101), vms[1].VMID)
+
+	vms, err = client.GetVMsByFilter(ctx, goproxmox.ByNode("pve-1"), goproxmox.ByTag("worker"))
+	require.NoError(t, err)
+	require.Len(t, vms, 1)
+	assert.Equal(t, uint64(100), vms[0].VMID)
+
+	vms, err = client.GetVMsByFilter(ctx, goproxmox.Or(goproxmox.ByNode("pve-2"), goproxmox.ByNameRegexp("^control-")))
+	require.NoError(t, err)
+	require.Len(t, vms, 2)
+	assert.Equal(t, uint64(101), vms[0].VMID)
+	assert.Equal(t, uint64(102), vms[1].VMID)
+
+	_, err = client.GetVMsByFilter(ctx, goproxmox.ByNode("pve-2"), goproxmox.Not(goproxmox.ByTag("worker")))
+	require.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+
+	vm, err := client.GetVMByFilter(ctx, goproxmox.ByTag("worker"), goproxmox.ByNode("pve-2"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(102), vm.VMID)
+
+	templates, err := client.GetVMTemplatesByFilter(ctx, goproxmox.ByNode("pve-1"), goproxmox.ByNode("pve-1"))
+	require.NoError(t, err)
+	assert.Len(t, templates, 1)
+
+	storages, err := client.GetClusterStoragesByFilter(ctx, goproxmox.ByNode("pve-1"), goproxmox.ByStatus("available"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, storages)
+
+	for _, st := range storages {
+		assert.Equal(t, "pve-1", st.Node)
+	}
+}
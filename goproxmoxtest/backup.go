@@ -0,0 +1,258 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmoxtest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	macRegexp = regexp.MustCompile(`^(\w+)=([0-9A-Fa-f]{2}(?::[0-9A-Fa-f]{2}){5})`)
+
+	backupFormats = map[string]string{
+		"":     "vma",
+		"0":    "vma",
+		"1":    "vma.lzo",
+		"gzip": "vma.gz",
+		"lzo":  "vma.lzo",
+		"zstd": "vma.zst",
+	}
+)
+
+// AddBackup adds a backup archive of the guest with the creation time to the storage, and returns its volume ID.
+// It stands for a backup written by another job.
+func (s *Server) AddBackup(node, storage string, vmid int, ctime time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.storages[storageKey(node, storage)]
+	if !ok {
+		return ""
+	}
+
+	volid := fmt.Sprintf("%s:backup/vzdump-qemu-%d-%s.vma", st.Storage, vmid, ctime.Format("2006_01_02-15_04_05"))
+	st.Volumes[volid] = &Volume{
+		VolID:   volid,
+		VMID:    vmid,
+		Format:  "vma",
+		Content: "backup",
+		CTime:   ctime.Unix(),
+		Config:  map[string]any{},
+	}
+
+	return volid
+}
+
+func (s *Server) registerBackupRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/vzdump", s.handle(s.createBackup))
+}
+
+func (s *Server) createBackup(r *http.Request, params map[string]any) (any, error) {
+	node := r.PathValue("node")
+
+	vmid, ok := paramInt(params, "vmid")
+	if !ok {
+		return nil, badRequest("vmid", "property is missing and it is not optional")
+	}
+
+	vm, ok := s.vms[vmid]
+	if !ok || vm.Node != node {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("guest %d is not on node '%s'", vmid, node)}
+	}
+
+	storage := paramString(params, "storage")
+	if storage == "" {
+		storage = "local"
+	}
+
+	st, err := s.lookupStorage(node, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.Contains(st.Content, "backup") {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("can't use storage '%s' for backups - wrong content type", storage)}
+	}
+
+	mode := paramString(params, "mode")
+	if mode == "" {
+		mode = "snapshot"
+	}
+
+	if mode != "snapshot" && mode != "suspend" && mode != "stop" {
+		return nil, badRequest("mode", fmt.Sprintf("value '%s' does not have a value in the enumeration 'snapshot, suspend, stop'", mode))
+	}
+
+	format, ok := backupFormats[paramString(params, "compress")]
+	if !ok {
+		return nil, badRequest("compress", fmt.Sprintf("value '%s' does not have a value in the enumeration '0, 1, gzip, lzo, zstd'", paramString(params, "compress")))
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	var size int64
+
+	for _, key := range sortedKeys(vm.Config) {
+		if isDiskKey(vm, key) && !strings.HasPrefix(key, "unused") {
+			size += diskSize(paramString(vm.Config, key))
+		}
+	}
+
+	ctime := time.Now()
+	volid := ""
+
+	// The archive name has a resolution of one second.
+	for {
+		volid = fmt.Sprintf("%s:backup/vzdump-%s-%d-%s.%s", st.Storage, vm.Type, vmid, ctime.Format("2006_01_02-15_04_05"), format)
+		if _, ok := st.Volumes[volid]; !ok {
+			break
+		}
+
+		ctime = ctime.Add(time.Second)
+	}
+
+	backup := &Volume{
+		VolID:   volid,
+		VMID:    vmid,
+		Size:    size,
+		Format:  format,
+		Content: "backup",
+		Notes:   expandNotes(paramString(params, "notes-template"), vm),
+		CTime:   ctime.Unix(),
+		Config:  maps.Clone(vm.Config),
+	}
+
+	delete(backup.Config, "lock")
+	delete(backup.Config, "parent")
+
+	task := s.newTaskWithResult(node, "vzdump", strconv.Itoa(vmid), vmid, "backup", func() {
+		st.Volumes[volid] = backup
+	},
+		fmt.Sprintf("INFO: starting new backup job: vzdump %d --mode %s --storage %s", vmid, mode, storage),
+		fmt.Sprintf("INFO: Starting Backup of VM %d (%s)", vmid, vm.Type),
+		fmt.Sprintf("INFO: creating vzdump archive '/mnt/pve/%s/dump/%s'", st.Storage, strings.TrimPrefix(volid, st.Storage+":backup/")),
+		fmt.Sprintf("INFO: Finished Backup of VM %d", vmid),
+	)
+
+	return task.UPID, nil
+}
+
+// restoreVM creates the VM from the backup archive of the create request.
+func (s *Server) restoreVM(node string, vmid int, params map[string]any) (any, error) {
+	archive := paramString(params, "archive")
+	storage, _, _ := strings.Cut(archive, ":")
+
+	st, err := s.lookupStorage(node, storage)
+	if err != nil {
+		return nil, err
+	}
+
+	backup, ok := st.Volumes[archive]
+	if !ok || backup.Content != "backup" {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("volume '%s' does not exist", archive)}
+	}
+
+	if vm, ok := s.vms[vmid]; ok {
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("unable to restore VM %d - VM %d already exists on node '%s'", vmid, vmid, vm.Node),
+		}
+	}
+
+	vm := &VM{Node: node, VMID: vmid, Type: "qemu", Status: "stopped", Config: maps.Clone(backup.Config)}
+	target := paramString(params, "storage")
+	log := []string{fmt.Sprintf("restore vma archive: %s", archive)}
+
+	// Check all the target storages before any volume is allocated.
+	for _, key := range sortedKeys(vm.Config) {
+		if !isDiskKey(vm, key) || strings.HasPrefix(key, "unused") {
+			continue
+		}
+
+		dst, _, _ := strings.Cut(diskVolume(paramString(vm.Config, key)), ":")
+		if target != "" {
+			dst = target
+		}
+
+		if _, err := s.lookupStorage(node, dst); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range sortedKeys(vm.Config) {
+		if strings.HasPrefix(key, "unused") {
+			delete(vm.Config, key)
+
+			continue
+		}
+
+		value := paramString(vm.Config, key)
+
+		if paramBool(params, "unique") && strings.HasPrefix(key, "net") {
+			vm.Config[key] = regenerateMAC(value)
+		}
+
+		if !isDiskKey(vm, key) {
+			continue
+		}
+
+		volume := diskVolume(value)
+
+		dst, _, _ := strings.Cut(volume, ":")
+		if target != "" {
+			dst = target
+		}
+
+		dstSt := s.storages[storageKey(node, dst)]
+		volid := dstSt.Storage + ":" + s.nextDiskName(dstSt, vmid)
+		dstSt.Volumes[volid] = &Volume{VolID: volid, VMID: vmid, Size: diskSize(value), Format: "raw", Content: "images"}
+
+		vm.Config[key] = volid + strings.TrimPrefix(value, volume)
+		log = append(log, fmt.Sprintf("map '%s' to '%s'", key, volid))
+	}
+
+	s.vms[vmid] = vm
+
+	return s.newTask(node, "qmrestore", strconv.Itoa(vmid), vmid, "create", log...).UPID, nil
+}
+
+// expandNotes expands the variables of the backup notes template.
+func expandNotes(template string, vm *VM) string {
+	return strings.NewReplacer(
+		"{{cluster}}", "pve",
+		"{{guestname}}", paramString(vm.Config, "name")+paramString(vm.Config, "hostname"),
+		"{{node}}", vm.Node,
+		"{{vmid}}", strconv.Itoa(vm.VMID),
+	).Replace(template)
+}
+
+// regenerateMAC replaces the MAC address of the network device with a random one.
+func regenerateMAC(net string) string {
+	b := make([]byte, 3)
+	rand.Read(b) //nolint:errcheck
+
+	return macRegexp.ReplaceAllString(net, fmt.Sprintf("${1}=BC:24:11:%02X:%02X:%02X", b[0], b[1], b[2]))
+}
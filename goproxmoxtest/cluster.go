@@ -0,0 +1,247 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmoxtest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+const firstVMID = 100
+
+func (s *Server) registerClusterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+APIPath+"/version", s.handle(s.getVersion))
+	mux.HandleFunc("GET "+APIPath+"/cluster/status", s.handle(s.getClusterStatus))
+	mux.HandleFunc("GET "+APIPath+"/cluster/resources", s.handle(s.getClusterResources))
+	mux.HandleFunc("GET "+APIPath+"/cluster/nextid", s.handle(s.getNextID))
+	mux.HandleFunc("GET "+APIPath+"/cluster/options", s.handle(s.getClusterOptions))
+	mux.HandleFunc("GET "+APIPath+"/cluster/ha/groups", s.handle(s.getHAGroups))
+	mux.HandleFunc("GET "+APIPath+"/nodes", s.handle(s.getNodes))
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/status", s.handle(s.getNodeStatus))
+}
+
+func (s *Server) getVersion(_ *http.Request, _ map[string]any) (any, error) {
+	return map[string]any{"release": "8.4", "repoid": "goproxmoxtest", "version": "8.4.0"}, nil
+}
+
+func (s *Server) getClusterStatus(_ *http.Request, _ map[string]any) (any, error) {
+	res := []map[string]any{
+		{"type": "cluster", "id": "cluster", "name": "goproxmoxtest", "version": 1, "quorate": 1, "nodes": len(s.nodes)},
+	}
+
+	for i, name := range sortedKeys(s.nodes) {
+		online := 0
+		if s.nodes[name].Status == "online" {
+			online = 1
+		}
+
+		res = append(res, map[string]any{"type": "node", "id": "node/" + name, "name": name, "nodeid": i + 1, "online": online})
+	}
+
+	return res, nil
+}
+
+func (s *Server) getClusterResources(_ *http.Request, params map[string]any) (any, error) {
+	typ := paramString(params, "type")
+	res := []map[string]any{}
+
+	if typ == "" || typ == "node" {
+		for _, name := range sortedKeys(s.nodes) {
+			res = append(res, map[string]any{
+				"id":     "node/" + name,
+				"type":   "node",
+				"node":   name,
+				"status": s.nodes[name].Status,
+				"maxcpu": 32,
+				"maxmem": 128 << 30,
+			})
+		}
+	}
+
+	if typ == "" || typ == "vm" {
+		for _, vmid := range s.sortedVMIDs() {
+			res = append(res, s.vmResource(s.vms[vmid]))
+		}
+	}
+
+	if typ == "" || typ == "storage" {
+		for _, key := range sortedKeys(s.storages) {
+			st := s.storages[key]
+
+			status := "available"
+			if s.nodes[st.Node] == nil || s.nodes[st.Node].Status != "online" {
+				status = "unknown"
+			}
+
+			shared := 0
+			if st.Shared {
+				shared = 1
+			}
+
+			res = append(res, map[string]any{
+				"id":         fmt.Sprintf("storage/%s/%s", st.Node, st.Storage),
+				"type":       "storage",
+				"node":       st.Node,
+				"storage":    st.Storage,
+				"status":     status,
+				"shared":     shared,
+				"content":    st.Content,
+				"plugintype": st.Type,
+				"maxdisk":    1 << 40,
+			})
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Server) vmResource(vm *VM) map[string]any {
+	status := vm.Status
+	if n := s.nodes[vm.Node]; n == nil || n.Status != "online" {
+		status = "unknown"
+	}
+
+	name := paramString(vm.Config, "name")
+	if vm.Type == "lxc" {
+		name = paramString(vm.Config, "hostname")
+	}
+
+	res := map[string]any{
+		"id":       fmt.Sprintf("%s/%d", vm.Type, vm.VMID),
+		"type":     vm.Type,
+		"node":     vm.Node,
+		"vmid":     vm.VMID,
+		"name":     name,
+		"status":   status,
+		"template": 0,
+		"maxcpu":   1,
+		"maxmem":   512 << 20,
+	}
+
+	if paramBool(vm.Config, "template") {
+		res["template"] = 1
+	}
+
+	if cores, ok := paramInt(vm.Config, "cores"); ok {
+		res["maxcpu"] = cores
+	}
+
+	if memory, ok := paramInt(vm.Config, "memory"); ok {
+		res["maxmem"] = memory << 20
+	}
+
+	for _, key := range []string{"tags", "pool", "lock"} {
+		if v := paramString(vm.Config, key); v != "" {
+			res[key] = v
+		}
+	}
+
+	return res
+}
+
+func (s *Server) getNextID(_ *http.Request, params map[string]any) (any, error) {
+	if v := paramString(params, "vmid"); v != "" {
+		vmid, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, badRequest("vmid", "type check ('integer') failed - got '"+v+"'")
+		}
+
+		if _, ok := s.vms[vmid]; ok {
+			return nil, badRequest("vmid", fmt.Sprintf("VM %d already exists", vmid))
+		}
+
+		return strconv.Itoa(vmid), nil
+	}
+
+	vmid := max(firstVMID, s.nextIDLower)
+	for s.vms[vmid] != nil {
+		vmid++
+	}
+
+	if s.nextIDUpper > 0 && vmid >= s.nextIDUpper {
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("unable to get any free VMID in range [%d, %d]", max(firstVMID, s.nextIDLower), s.nextIDUpper-1),
+		}
+	}
+
+	return strconv.Itoa(vmid), nil
+}
+
+func (s *Server) getClusterOptions(_ *http.Request, _ map[string]any) (any, error) {
+	res := map[string]any{}
+
+	if s.nextIDLower > 0 || s.nextIDUpper > 0 {
+		nextID := map[string]any{}
+
+		if s.nextIDLower > 0 {
+			nextID["lower"] = strconv.Itoa(s.nextIDLower)
+		}
+
+		if s.nextIDUpper > 0 {
+			nextID["upper"] = strconv.Itoa(s.nextIDUpper)
+		}
+
+		res["next-id"] = nextID
+	}
+
+	return res, nil
+}
+
+func (s *Server) getHAGroups(_ *http.Request, _ map[string]any) (any, error) {
+	return []any{}, nil
+}
+
+func (s *Server) getNodes(_ *http.Request, _ map[string]any) (any, error) {
+	res := []map[string]any{}
+
+	for _, name := range sortedKeys(s.nodes) {
+		res = append(res, map[string]any{
+			"id":     "node/" + name,
+			"type":   "node",
+			"node":   name,
+			"status": s.nodes[name].Status,
+		})
+	}
+
+	return res, nil
+}
+
+func (s *Server) getNodeStatus(r *http.Request, _ map[string]any) (any, error) {
+	if err := s.checkNode(r.PathValue("node")); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPrivilege(r, "Sys.Audit"); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{"uptime": 3600, "pveversion": "pve-manager/8.4.0"}, nil
+}
+
+func (s *Server) sortedVMIDs() []int {
+	ids := make([]int, 0, len(s.vms))
+	for id := range s.vms {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
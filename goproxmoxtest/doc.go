@@ -0,0 +1,31 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package goproxmoxtest implements an in-memory Proxmox VE API server for tests.
+//
+// The server keeps a small stateful model of a cluster (nodes, storages,
+// virtual machines, containers, templates, backups, pools and tasks) and serves the subset of the API
+// used by goproxmox.APIClient:
+//
+//	srv := goproxmoxtest.NewServer()
+//	defer srv.Close()
+//
+//	srv.AddNode("pve-1")
+//	srv.AddStorage("pve-1", "local-lvm", false)
+//	srv.AddTemplate("pve-1", 9000, map[string]any{"name": "talos", "scsi0": "local-lvm:base-9000-disk-0,size=10G"})
+//
+//	client, _ := goproxmox.NewAPIClient(srv.URL())
+package goproxmoxtest
@@ -0,0 +1,424 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmoxtest
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ctDiskKeyRegexp = regexp.MustCompile(`^(rootfs|mp\d+|unused\d+)$`)
+	ctStatusAction  = map[string]string{
+		"start":    "vzstart",
+		"stop":     "vzstop",
+		"shutdown": "vzshutdown",
+		"reboot":   "vzreboot",
+	}
+)
+
+// AddContainer adds a stopped container with the given configuration.
+func (s *Server) AddContainer(node string, vmid int, config map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vms[vmid] = &VM{
+		Node:   node,
+		VMID:   vmid,
+		Type:   "lxc",
+		Status: "stopped",
+		Config: normalizeConfig(config),
+	}
+}
+
+// AddContainerTemplate adds a container template with the given configuration.
+func (s *Server) AddContainerTemplate(node string, vmid int, config map[string]any) {
+	cfg := maps.Clone(config)
+	if cfg == nil {
+		cfg = map[string]any{}
+	}
+
+	cfg["template"] = 1
+
+	s.AddContainer(node, vmid, cfg)
+}
+
+func (s *Server) registerLXCRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/lxc", s.handle(s.listContainers))
+	mux.HandleFunc("DELETE "+APIPath+"/nodes/{node}/lxc/{vmid}", s.handle(s.deleteContainer))
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/lxc/{vmid}/status/current", s.handle(s.getContainerStatus))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/lxc/{vmid}/status/{action}", s.handle(s.changeContainerStatus))
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/lxc/{vmid}/config", s.handle(s.getContainerConfig))
+	mux.HandleFunc("PUT "+APIPath+"/nodes/{node}/lxc/{vmid}/config", s.handle(s.updateContainerConfig))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/lxc/{vmid}/clone", s.handle(s.cloneContainer))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/lxc/{vmid}/migrate", s.handle(s.migrateContainer))
+	mux.HandleFunc("PUT "+APIPath+"/nodes/{node}/lxc/{vmid}/resize", s.handle(s.resizeContainerDisk))
+}
+
+func (s *Server) lookupCTRequest(r *http.Request) (*VM, error) {
+	vmid, err := pathVMID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.lookupGuest(r.PathValue("node"), "lxc", vmid)
+}
+
+func (s *Server) listContainers(r *http.Request, _ map[string]any) (any, error) {
+	node := r.PathValue("node")
+	if err := s.checkNode(node); err != nil {
+		return nil, err
+	}
+
+	res := []map[string]any{}
+
+	for _, vmid := range s.sortedVMIDs() {
+		if vm := s.vms[vmid]; vm.Node == node && vm.Type == "lxc" {
+			res = append(res, ctStatus(vm))
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Server) getContainerStatus(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupCTRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ctStatus(vm), nil
+}
+
+func ctStatus(vm *VM) map[string]any {
+	res := map[string]any{
+		"vmid":   vm.VMID,
+		"name":   paramString(vm.Config, "hostname"),
+		"status": vm.Status,
+		"type":   "lxc",
+		"cpus":   1,
+		"maxmem": 512 << 20,
+	}
+
+	if cores, ok := paramInt(vm.Config, "cores"); ok {
+		res["cpus"] = cores
+	}
+
+	if memory, ok := paramInt(vm.Config, "memory"); ok {
+		res["maxmem"] = memory << 20
+	}
+
+	if paramBool(vm.Config, "template") {
+		res["template"] = 1
+	}
+
+	for _, key := range []string{"tags", "lock"} {
+		if v := paramString(vm.Config, key); v != "" {
+			res[key] = v
+		}
+	}
+
+	return res
+}
+
+func (s *Server) changeContainerStatus(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupCTRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	action := r.PathValue("action")
+
+	taskType, ok := ctStatusAction[action]
+	if !ok {
+		return nil, &apiError{status: http.StatusNotImplemented, message: fmt.Sprintf("Method 'POST /nodes/%s/lxc/%d/status/%s' not implemented", vm.Node, vm.VMID, action)}
+	}
+
+	if action != "stop" {
+		if err := checkLock(vm, params); err != nil {
+			return nil, err
+		}
+	}
+
+	if paramBool(vm.Config, "template") {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("CT %d is a template - %s failed", vm.VMID, action)}
+	}
+
+	switch action {
+	case "start":
+		if vm.Status == "running" {
+			return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("CT %d already running", vm.VMID)}
+		}
+
+		vm.Status = "running"
+	case "stop", "shutdown":
+		vm.Status = "stopped"
+	case "reboot":
+		if vm.Status != "running" {
+			return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("CT %d not running", vm.VMID)}
+		}
+	}
+
+	return s.newTask(vm.Node, taskType, strconv.Itoa(vm.VMID), 0, "").UPID, nil
+}
+
+func (s *Server) deleteContainer(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupCTRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	if vm.Status == "running" {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("CT %d is running - destroy failed", vm.VMID)}
+	}
+
+	for _, key := range sortedKeys(vm.Config) {
+		if ctDiskKeyRegexp.MatchString(key) {
+			s.deleteVolume(vm.Node, diskVolume(paramString(vm.Config, key)))
+		}
+	}
+
+	delete(s.vms, vm.VMID)
+
+	return s.newTask(vm.Node, "vzdestroy", strconv.Itoa(vm.VMID), 0, "").UPID, nil
+}
+
+func (s *Server) getContainerConfig(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupCTRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	config := maps.Clone(vm.Config)
+	config["digest"] = configDigest(vm.Config)
+
+	return config, nil
+}
+
+// updateContainerConfig updates the container config synchronously.
+// A deleted mount point is kept as an unused volume.
+func (s *Server) updateContainerConfig(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupCTRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLock(vm, params); err != nil {
+		return nil, err
+	}
+
+	if digest := paramString(params, "digest"); digest != "" && digest != configDigest(vm.Config) {
+		return nil, &apiError{status: http.StatusInternalServerError, message: "detected modified configuration - file changed by other user? Try again."}
+	}
+
+	for _, key := range strings.Split(paramString(params, "delete"), ",") {
+		key = strings.TrimSpace(key)
+
+		value := paramString(vm.Config, key)
+		if value == "" {
+			continue
+		}
+
+		if key == "rootfs" {
+			return nil, badRequest("delete", "unable to delete required option 'rootfs'")
+		}
+
+		delete(vm.Config, key)
+
+		if strings.HasPrefix(key, "mp") {
+			vm.Config[nextUnusedKey(vm)] = diskVolume(value)
+		} else if strings.HasPrefix(key, "unused") {
+			s.deleteVolume(vm.Node, diskVolume(value))
+		}
+	}
+
+	update := maps.Clone(params)
+	for _, key := range []string{"node", "vmid", "delete", "digest", "skiplock"} {
+		delete(update, key)
+	}
+
+	maps.Copy(vm.Config, normalizeConfig(update))
+
+	if err := s.allocateDisks(vm); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (s *Server) cloneContainer(r *http.Request, params map[string]any) (any, error) {
+	src, err := s.lookupCTRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	newid, ok := paramInt(params, "newid")
+	if !ok {
+		return nil, badRequest("newid", "property is missing and it is not optional")
+	}
+
+	if _, ok := s.vms[newid]; ok {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("unable to create CT %d - container already exists", newid)}
+	}
+
+	if err := checkLock(src, nil); err != nil {
+		return nil, err
+	}
+
+	target := paramString(params, "target")
+	if target == "" {
+		target = src.Node
+	}
+
+	if err := s.checkNode(target); err != nil {
+		return nil, err
+	}
+
+	template := paramBool(src.Config, "template")
+	full := paramBool(params, "full") || !template
+	storage := paramString(params, "storage")
+
+	if storage != "" && !full {
+		return nil, badRequest("storage", "Storage migration is only allowed for full clones")
+	}
+
+	config := maps.Clone(src.Config)
+	delete(config, "template")
+	delete(config, "lock")
+
+	if hostname := paramString(params, "hostname"); hostname != "" {
+		config["hostname"] = hostname
+	}
+
+	for _, key := range []string{"description", "pool"} {
+		if v := paramString(params, key); v != "" {
+			config[key] = v
+		}
+	}
+
+	kind := "linked"
+	if full {
+		kind = "full"
+	}
+
+	log := []string{}
+
+	for _, key := range sortedKeys(src.Config) {
+		if !ctDiskKeyRegexp.MatchString(key) || strings.HasPrefix(key, "unused") {
+			continue
+		}
+
+		disk, _, err := s.cloneDisk(src, key, newid, target, storage, full)
+		if err != nil {
+			return nil, err
+		}
+
+		config[key] = disk
+
+		log = append(log, fmt.Sprintf("create %s clone of mount point %s (%s)", kind, key, diskVolume(paramString(src.Config, key))))
+	}
+
+	s.vms[newid] = &VM{Node: target, VMID: newid, Type: "lxc", Status: "stopped", Config: config}
+
+	return s.newTask(src.Node, "vzclone", strconv.Itoa(src.VMID), newid, "clone", log...).UPID, nil
+}
+
+func (s *Server) migrateContainer(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupCTRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	target := paramString(params, "target")
+	if target == "" {
+		return nil, badRequest("target", "property is missing and it is not optional")
+	}
+
+	if target == vm.Node {
+		return nil, badRequest("target", "target is local node.")
+	}
+
+	if err := s.checkNode(target); err != nil {
+		return nil, err
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	if vm.Status == "running" && !paramBool(params, "restart") {
+		return nil, &apiError{status: http.StatusInternalServerError, message: "lxc live migration is currently not implemented - use 'restart' mode"}
+	}
+
+	// Local volumes are copied to the storage with the same name on the target node.
+	moves := map[string]*Storage{}
+
+	for _, key := range sortedKeys(vm.Config) {
+		if !ctDiskKeyRegexp.MatchString(key) {
+			continue
+		}
+
+		storage, _, _ := strings.Cut(diskVolume(paramString(vm.Config, key)), ":")
+
+		st, ok := s.storages[storageKey(vm.Node, storage)]
+		if !ok || st.Shared {
+			continue
+		}
+
+		dst, err := s.lookupStorage(target, storage)
+		if err != nil {
+			return nil, err
+		}
+
+		moves[key] = dst
+	}
+
+	log := []string{fmt.Sprintf("starting migration of CT %d to node '%s'", vm.VMID, target)}
+
+	task := s.newTaskWithResult(vm.Node, "vzmigrate", strconv.Itoa(vm.VMID), vm.VMID, "migrate", func() {
+		s.moveGuest(vm, target, moves)
+	}, log...)
+
+	return task.UPID, nil
+}
+
+func (s *Server) resizeContainerDisk(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupCTRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.resizeDisk(vm, params)
+}
+
+// nextUnusedKey returns the first free unusedN key of the config.
+func nextUnusedKey(vm *VM) string {
+	for i := 0; ; i++ {
+		key := fmt.Sprintf("unused%d", i)
+		if _, ok := vm.Config[key]; !ok {
+			return key
+		}
+	}
+}
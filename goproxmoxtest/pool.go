@@ -0,0 +1,85 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmoxtest
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+)
+
+var poolIDRegexp = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._\-]*$`)
+
+func (s *Server) registerPoolRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+APIPath+"/pools", s.handle(s.getPools))
+	mux.HandleFunc("POST "+APIPath+"/pools", s.handle(s.createPool))
+	mux.HandleFunc("DELETE "+APIPath+"/pools/{poolid}", s.handle(s.deletePool))
+}
+
+// AddPool adds a resource pool with the comment.
+func (s *Server) AddPool(poolid, comment string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pools[poolid] = comment
+}
+
+// Pools returns the comments of the resource pools by pool ID.
+func (s *Server) Pools() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return maps.Clone(s.pools)
+}
+
+func (s *Server) getPools(_ *http.Request, _ map[string]any) (any, error) {
+	res := []map[string]any{}
+
+	for _, id := range sortedKeys(s.pools) {
+		res = append(res, map[string]any{"poolid": id, "comment": s.pools[id]})
+	}
+
+	return res, nil
+}
+
+func (s *Server) createPool(_ *http.Request, params map[string]any) (any, error) {
+	id := paramString(params, "poolid")
+	if !poolIDRegexp.MatchString(id) {
+		return nil, badRequest("poolid", "invalid format - invalid pool ID")
+	}
+
+	if _, ok := s.pools[id]; ok {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("create pool failed: pool '%s' already exists", id)}
+	}
+
+	s.pools[id] = paramString(params, "comment")
+
+	return nil, nil
+}
+
+func (s *Server) deletePool(r *http.Request, _ map[string]any) (any, error) {
+	id := r.PathValue("poolid")
+
+	if _, ok := s.pools[id]; !ok {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("delete pool failed: pool '%s' does not exist", id)}
+	}
+
+	delete(s.pools, id)
+
+	return nil, nil
+}
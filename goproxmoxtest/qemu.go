@@ -0,0 +1,1148 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmoxtest
+
+import (
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	diskKeyRegexp  = regexp.MustCompile(`^(virtio|scsi|sata|ide|efidisk|tpmstate|unused)\d+$`)
+	hostDevRegexp  = regexp.MustCompile(`^(hostpci|usb)\d+$`)
+	newDiskRegexp  = regexp.MustCompile(`^([\w-]+):(\d+(?:\.\d+)?)$`)
+	intConfigKeys  = []string{"template", "autostart", "tablet", "kvm", "protection", "onboot", "acpi", "sockets", "cores", "cpuunits", "vcpus", "numa", "balloon"}
+	vmStatusAction = map[string]string{
+		"start":    "qmstart",
+		"stop":     "qmstop",
+		"shutdown": "qmshutdown",
+		"reboot":   "qmreboot",
+		"reset":    "qmreset",
+		"suspend":  "qmsuspend",
+		"resume":   "qmresume",
+	}
+)
+
+func (s *Server) registerQemuRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/qemu", s.handle(s.listVMs))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu", s.handle(s.createVM))
+	mux.HandleFunc("DELETE "+APIPath+"/nodes/{node}/qemu/{vmid}", s.handle(s.deleteVM))
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/qemu/{vmid}/status/current", s.handle(s.getVMStatus))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu/{vmid}/status/{action}", s.handle(s.changeVMStatus))
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/qemu/{vmid}/config", s.handle(s.getVMConfig))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu/{vmid}/config", s.handle(s.updateVMConfig(true)))
+	mux.HandleFunc("PUT "+APIPath+"/nodes/{node}/qemu/{vmid}/config", s.handle(s.updateVMConfig(false)))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu/{vmid}/clone", s.handle(s.cloneVM))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu/{vmid}/template", s.handle(s.templateVM))
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/qemu/{vmid}/migrate", s.handle(s.getMigratePreconditions))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu/{vmid}/migrate", s.handle(s.migrateVM))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu/{vmid}/remote_migrate", s.handle(s.remoteMigrateVM))
+	mux.HandleFunc("PUT "+APIPath+"/nodes/{node}/qemu/{vmid}/resize", s.handle(s.resizeVMDisk))
+	mux.HandleFunc("PUT "+APIPath+"/nodes/{node}/qemu/{vmid}/unlink", s.handle(s.unlinkVMDisk))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu/{vmid}/move_disk", s.handle(s.moveVMDisk))
+	mux.HandleFunc("PUT "+APIPath+"/nodes/{node}/qemu/{vmid}/cloudinit", s.handle(s.regenerateCloudInit))
+}
+
+func (s *Server) listVMs(r *http.Request, _ map[string]any) (any, error) {
+	node := r.PathValue("node")
+	if err := s.checkNode(node); err != nil {
+		return nil, err
+	}
+
+	res := []map[string]any{}
+
+	for _, vmid := range s.sortedVMIDs() {
+		if vm := s.vms[vmid]; vm.Node == node && vm.Type == "qemu" {
+			res = append(res, vmStatus(vm))
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Server) createVM(r *http.Request, params map[string]any) (any, error) {
+	node := r.PathValue("node")
+	if err := s.checkNode(node); err != nil {
+		return nil, err
+	}
+
+	vmid, ok := paramInt(params, "vmid")
+	if !ok {
+		return nil, badRequest("vmid", "property is missing and it is not optional")
+	}
+
+	if paramString(params, "archive") != "" {
+		return s.restoreVM(node, vmid, params)
+	}
+
+	if _, ok := s.vms[vmid]; ok {
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("unable to create VM %d - VM %d already exists on node '%s'", vmid, vmid, s.vms[vmid].Node),
+		}
+	}
+
+	config := maps.Clone(params)
+	delete(config, "vmid")
+	delete(config, "node")
+
+	vm := &VM{Node: node, VMID: vmid, Type: "qemu", Status: "stopped", Config: normalizeConfig(config)}
+
+	if err := s.allocateDisks(vm); err != nil {
+		return nil, err
+	}
+
+	s.vms[vmid] = vm
+
+	return s.newTask(node, "qmcreate", strconv.Itoa(vmid), vmid, "create").UPID, nil
+}
+
+func (s *Server) deleteVM(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	if vm.Status == "running" {
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("VM %d is running - destroy failed", vm.VMID),
+		}
+	}
+
+	for _, key := range sortedKeys(vm.Config) {
+		if diskKeyRegexp.MatchString(key) {
+			s.deleteVolume(vm.Node, diskVolume(paramString(vm.Config, key)))
+		}
+	}
+
+	delete(s.vms, vm.VMID)
+
+	return s.newTask(vm.Node, "qmdestroy", strconv.Itoa(vm.VMID), 0, "").UPID, nil
+}
+
+func (s *Server) getVMStatus(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return vmStatus(vm), nil
+}
+
+func vmStatus(vm *VM) map[string]any {
+	res := map[string]any{
+		"vmid":      vm.VMID,
+		"name":      paramString(vm.Config, "name"),
+		"status":    vm.Status,
+		"qmpstatus": vm.QMPStatus,
+		"cpus":      1,
+		"maxmem":    512 << 20,
+	}
+
+	if vm.QMPStatus == "" {
+		res["qmpstatus"] = vm.Status
+	}
+
+	if cores, ok := paramInt(vm.Config, "cores"); ok {
+		res["cpus"] = cores
+	}
+
+	if memory, ok := paramInt(vm.Config, "memory"); ok {
+		res["maxmem"] = memory << 20
+	}
+
+	if paramBool(vm.Config, "template") {
+		res["template"] = 1
+	}
+
+	for _, key := range []string{"tags", "lock"} {
+		if v := paramString(vm.Config, key); v != "" {
+			res[key] = v
+		}
+	}
+
+	return res
+}
+
+func (s *Server) changeVMStatus(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	action := r.PathValue("action")
+
+	taskType, ok := vmStatusAction[action]
+	if !ok {
+		return nil, &apiError{status: http.StatusNotImplemented, message: fmt.Sprintf("Method 'POST /nodes/%s/qemu/%d/status/%s' not implemented", vm.Node, vm.VMID, action)}
+	}
+
+	// A VM suspended to disk is resumed by starting it.
+	resume := action == "start" && paramString(vm.Config, "lock") == "suspended"
+
+	if action != "stop" && action != "resume" && !resume {
+		if err := checkLock(vm, params); err != nil {
+			return nil, err
+		}
+	}
+
+	if paramBool(vm.Config, "template") {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("VM %d is a template - %s failed", vm.VMID, action)}
+	}
+
+	switch action {
+	case "start":
+		if vm.Status == "running" {
+			return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("VM %d already running", vm.VMID)}
+		}
+
+		if paramString(vm.Config, "lock") == "suspended" {
+			delete(vm.Config, "lock")
+		}
+
+		vm.Status, vm.QMPStatus = "running", "running"
+	case "stop":
+		delete(vm.Config, "lock")
+
+		vm.Status, vm.QMPStatus = "stopped", "stopped"
+	case "shutdown":
+		// The guest powers off only if the shutdown task succeeds, see FailTask.
+		// With forceStop a guest which does not power off is stopped by the task.
+		log := []string{}
+
+		if _, ok := s.failures[taskType]; ok && paramBool(params, "forceStop") {
+			delete(s.failures, taskType)
+
+			log = append(log, "VM still running - terminating now with SIGTERM")
+		}
+
+		task := s.newTaskWithResult(vm.Node, taskType, strconv.Itoa(vm.VMID), 0, "", func() {
+			vm.Status, vm.QMPStatus = "stopped", "stopped"
+		}, log...)
+
+		return task.UPID, nil
+	case "reboot", "reset":
+		if vm.Status != "running" {
+			return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("VM %d not running", vm.VMID)}
+		}
+
+		vm.QMPStatus = "running"
+	case "suspend":
+		if vm.Status != "running" {
+			return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("VM %d not running", vm.VMID)}
+		}
+
+		if storage := paramString(params, "statestorage"); storage != "" {
+			if !paramBool(params, "todisk") {
+				return nil, badRequest("statestorage", "value requires 'todisk' option")
+			}
+
+			if _, err := s.lookupStorage(vm.Node, storage); err != nil {
+				return nil, err
+			}
+		}
+
+		vm.QMPStatus = "paused"
+
+		if paramBool(params, "todisk") {
+			vm.Status, vm.QMPStatus = "stopped", "stopped"
+			vm.Config["lock"] = "suspended"
+		}
+	case "resume":
+		if vm.Status != "running" {
+			return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("VM %d not running", vm.VMID)}
+		}
+
+		vm.QMPStatus = "running"
+	}
+
+	return s.newTask(vm.Node, taskType, strconv.Itoa(vm.VMID), 0, "").UPID, nil
+}
+
+func (s *Server) getVMConfig(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	config := maps.Clone(vm.Config)
+	config["digest"] = configDigest(vm.Config)
+
+	return config, nil
+}
+
+func (s *Server) updateVMConfig(async bool) handlerFunc {
+	return func(r *http.Request, params map[string]any) (any, error) {
+		vm, err := s.lookupVMRequest(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkLock(vm, params); err != nil {
+			return nil, err
+		}
+
+		if digest := paramString(params, "digest"); digest != "" && digest != configDigest(vm.Config) {
+			return nil, &apiError{status: http.StatusInternalServerError, message: "detected modified configuration - file changed by other user? Try again."}
+		}
+
+		for _, key := range strings.Split(paramString(params, "delete"), ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				delete(vm.Config, key)
+			}
+		}
+
+		update := maps.Clone(params)
+		for _, key := range []string{"node", "vmid", "delete", "digest", "skiplock"} {
+			delete(update, key)
+		}
+
+		maps.Copy(vm.Config, normalizeConfig(update))
+
+		if err := s.allocateDisks(vm); err != nil {
+			return nil, err
+		}
+
+		if !async {
+			return nil, nil
+		}
+
+		return s.newTask(vm.Node, "qmconfig", strconv.Itoa(vm.VMID), 0, "").UPID, nil
+	}
+}
+
+func (s *Server) cloneVM(r *http.Request, params map[string]any) (any, error) {
+	src, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	newid, ok := paramInt(params, "newid")
+	if !ok {
+		return nil, badRequest("newid", "property is missing and it is not optional")
+	}
+
+	if _, ok := s.vms[newid]; ok {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("unable to create VM %d: config file already exists", newid)}
+	}
+
+	if err := checkLock(src, nil); err != nil {
+		return nil, err
+	}
+
+	target := paramString(params, "target")
+	if target == "" {
+		target = src.Node
+	}
+
+	if err := s.checkNode(target); err != nil {
+		return nil, err
+	}
+
+	template := paramBool(src.Config, "template")
+	full := paramBool(params, "full") || !template
+	storage := paramString(params, "storage")
+
+	if storage != "" && !full {
+		return nil, badRequest("storage", "Storage migration is only allowed for full clones")
+	}
+
+	config := maps.Clone(src.Config)
+	delete(config, "template")
+	delete(config, "lock")
+
+	config["name"] = fmt.Sprintf("Copy-of-VM-%s", paramString(src.Config, "name"))
+	if name := paramString(params, "name"); name != "" {
+		config["name"] = name
+	}
+
+	for _, key := range []string{"description", "pool"} {
+		if v := paramString(params, key); v != "" {
+			config[key] = v
+		}
+	}
+
+	if smbios := paramString(config, "smbios1"); smbios != "" {
+		config["smbios1"] = regenerateUUID(smbios)
+	}
+
+	log := []string{}
+
+	for _, key := range sortedKeys(src.Config) {
+		if !diskKeyRegexp.MatchString(key) || strings.Contains(paramString(src.Config, key), "media=cdrom") {
+			continue
+		}
+
+		disk, size, err := s.cloneDisk(src, key, newid, target, storage, full)
+		if err != nil {
+			return nil, err
+		}
+
+		config[key] = disk
+
+		if !full {
+			log = append(log, fmt.Sprintf("create linked clone of drive %s (%s)", key, diskVolume(paramString(src.Config, key))))
+
+			continue
+		}
+
+		log = append(log, fmt.Sprintf("create full clone of drive %s (%s)", key, diskVolume(paramString(src.Config, key))))
+		for _, percent := range []int64{0, 50, 100} {
+			log = append(log, fmt.Sprintf("transferred %s of %s (%d.00%%)", logSize(size*percent/100), logSize(size), percent))
+		}
+	}
+
+	s.vms[newid] = &VM{Node: target, VMID: newid, Type: "qemu", Status: "stopped", Config: config}
+
+	return s.newTask(src.Node, "qmclone", strconv.Itoa(src.VMID), newid, "clone", log...).UPID, nil
+}
+
+func (s *Server) cloneDisk(src *VM, key string, newid int, target, storage string, full bool) (string, int64, error) {
+	value := paramString(src.Config, key)
+	volume := diskVolume(value)
+
+	srcStorage, name, _ := strings.Cut(volume, ":")
+	if storage == "" {
+		storage = srcStorage
+	}
+
+	st, err := s.lookupStorage(target, storage)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if target != src.Node && !st.Shared {
+		return "", 0, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("can't clone VM to node '%s' (VM uses local storage)", target)}
+	}
+
+	var size int64
+	if srcSt, ok := s.storages[storageKey(src.Node, srcStorage)]; ok {
+		if v, ok := srcSt.Volumes[volume]; ok {
+			size = v.Size
+		}
+	}
+
+	diskName := s.nextDiskName(st, newid)
+	if !full {
+		diskName = name + "/" + diskName
+	}
+
+	volid := st.Storage + ":" + diskName
+	st.Volumes[volid] = &Volume{VolID: volid, VMID: newid, Size: size, Format: "raw", Content: "images"}
+
+	return volid + strings.TrimPrefix(value, volume), size, nil
+}
+
+// logSize formats the size as the qemu-img progress output does.
+func logSize(size int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	v := float64(size)
+
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
+
+func (s *Server) templateVM(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	if vm.Status == "running" {
+		return nil, &apiError{status: http.StatusInternalServerError, message: "you can't convert a running VM to a template"}
+	}
+
+	for _, key := range sortedKeys(vm.Config) {
+		if !diskKeyRegexp.MatchString(key) {
+			continue
+		}
+
+		value := paramString(vm.Config, key)
+		volume := diskVolume(value)
+
+		storage, name, _ := strings.Cut(volume, ":")
+		if !strings.HasPrefix(name, "vm-") {
+			continue
+		}
+
+		if st, ok := s.storages[storageKey(vm.Node, storage)]; ok {
+			if v, ok := st.Volumes[volume]; ok {
+				delete(st.Volumes, volume)
+
+				v.VolID = storage + ":base-" + strings.TrimPrefix(name, "vm-")
+				st.Volumes[v.VolID] = v
+
+				vm.Config[key] = v.VolID + strings.TrimPrefix(value, volume)
+			}
+		}
+	}
+
+	vm.Config["template"] = 1
+
+	return s.newTask(vm.Node, "qmtemplate", strconv.Itoa(vm.VMID), vm.VMID, "create").UPID, nil
+}
+
+func (s *Server) migrateVM(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	target := paramString(params, "target")
+	if target == "" {
+		return nil, badRequest("target", "property is missing and it is not optional")
+	}
+
+	if target == vm.Node {
+		return nil, badRequest("target", "target is local node.")
+	}
+
+	if err := s.checkNode(target); err != nil {
+		return nil, err
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	if vm.Status == "running" && !paramBool(params, "online") {
+		return nil, &apiError{status: http.StatusInternalServerError, message: "can't migrate running VM without --online"}
+	}
+
+	if local, _ := localResources(vm); len(local) > 0 {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("can't migrate VM which uses local devices: %s", strings.Join(local, ", "))}
+	}
+
+	moves := map[string]*Storage{}
+
+	for _, key := range sortedKeys(vm.Config) {
+		if !diskKeyRegexp.MatchString(key) || strings.Contains(paramString(vm.Config, key), "media=cdrom") {
+			continue
+		}
+
+		volume := diskVolume(paramString(vm.Config, key))
+		storage, _, _ := strings.Cut(volume, ":")
+
+		st, ok := s.storages[storageKey(vm.Node, storage)]
+		if !ok || st.Shared {
+			continue
+		}
+
+		// Offline migrations copy the local disks, live migrations mirror them only on request.
+		if vm.Status == "running" && !paramBool(params, "with-local-disks") {
+			return nil, &apiError{
+				status:  http.StatusInternalServerError,
+				message: fmt.Sprintf("can't migrate local disk '%s': can't live migrate attached local disks without with-local-disks option", volume),
+			}
+		}
+
+		dst, err := s.lookupStorage(target, mapStorage(paramString(params, "targetstorage"), storage))
+		if err != nil {
+			return nil, err
+		}
+
+		moves[key] = dst
+	}
+
+	// The VM and its local disks move to the target node when the migration succeeds.
+	log := []string{fmt.Sprintf("starting migration of VM %d to node '%s'", vm.VMID, target)}
+
+	task := s.newTaskWithResult(vm.Node, "qmigrate", strconv.Itoa(vm.VMID), vm.VMID, "migrate", func() {
+		s.moveGuest(vm, target, moves)
+	}, log...)
+
+	return task.UPID, nil
+}
+
+// remoteMigrateVM migrates the VM to another cluster, which is not modeled.
+// The VM is deleted, or kept stopped, on this server when the migration succeeds.
+func (s *Server) remoteMigrateVM(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := map[string]string{}
+
+	for _, opt := range strings.Split(paramString(params, "target-endpoint"), ",") {
+		if k, v, ok := strings.Cut(opt, "="); ok {
+			endpoint[k] = v
+		}
+	}
+
+	if endpoint["host"] == "" || !strings.HasPrefix(endpoint["apitoken"], "PVEAPIToken=") {
+		return nil, badRequest("target-endpoint", "invalid format - missing host or apitoken")
+	}
+
+	for _, key := range []string{"target-storage", "target-bridge"} {
+		if paramString(params, key) == "" {
+			return nil, badRequest(key, "property is missing and it is not optional")
+		}
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	if vm.Status == "running" && !paramBool(params, "online") {
+		return nil, &apiError{status: http.StatusInternalServerError, message: "can't migrate running VM without --online"}
+	}
+
+	if local, mapped := localResources(vm); len(local)+len(mapped) > 0 {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("can't migrate VM which uses local devices: %s", strings.Join(append(local, mapped...), ", "))}
+	}
+
+	targetVMID := vm.VMID
+	if id, ok := paramInt(params, "target-vmid"); ok {
+		targetVMID = id
+	}
+
+	log := []string{fmt.Sprintf("remote migration of VM %d to VM %d on %s", vm.VMID, targetVMID, endpoint["host"])}
+
+	for _, key := range sortedKeys(vm.Config) {
+		value := paramString(vm.Config, key)
+
+		switch {
+		case diskKeyRegexp.MatchString(key) && !strings.Contains(value, "media=cdrom"):
+			storage, _, _ := strings.Cut(diskVolume(value), ":")
+			log = append(log, fmt.Sprintf("mapped: %s from %s to %s", key, diskVolume(value), mapStorage(paramString(params, "target-storage"), storage)))
+		case strings.HasPrefix(key, "net"):
+			bridge := ""
+
+			for _, opt := range strings.Split(value, ",") {
+				if v, ok := strings.CutPrefix(opt, "bridge="); ok {
+					bridge = v
+				}
+			}
+
+			log = append(log, fmt.Sprintf("mapped: %s from %s to %s", key, bridge, mapStorage(paramString(params, "target-bridge"), bridge)))
+		}
+	}
+
+	task := s.newTaskWithResult(vm.Node, "qmigrate", strconv.Itoa(vm.VMID), vm.VMID, "migrate", func() {
+		vm.Status, vm.QMPStatus = "stopped", "stopped"
+
+		if !paramBool(params, "delete") {
+			return
+		}
+
+		for _, key := range sortedKeys(vm.Config) {
+			if isDiskKey(vm, key) {
+				s.deleteVolume(vm.Node, diskVolume(paramString(vm.Config, key)))
+			}
+		}
+
+		delete(s.vms, vm.VMID)
+	}, log...)
+
+	return task.UPID, nil
+}
+
+func (s *Server) getMigratePreconditions(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if target, ok := params["target"]; ok && target == "" {
+		return nil, badRequest("target", "invalid format - value does not look like a valid node name")
+	}
+
+	running := 0
+	if vm.Status == "running" {
+		running = 1
+	}
+
+	storages := []string{}
+	localDisks := []map[string]any{}
+
+	for _, key := range sortedKeys(vm.Config) {
+		value := paramString(vm.Config, key)
+		if !diskKeyRegexp.MatchString(key) || diskVolume(value) == "none" {
+			continue
+		}
+
+		volume := diskVolume(value)
+		storage, _, _ := strings.Cut(volume, ":")
+
+		if !slices.Contains(storages, storage) {
+			storages = append(storages, storage)
+		}
+
+		if st, ok := s.storages[storageKey(vm.Node, storage)]; ok && st.Shared {
+			continue
+		}
+
+		cdrom := 0
+		if strings.Contains(value, "media=cdrom") {
+			cdrom = 1
+		}
+
+		unused := 0
+		if strings.HasPrefix(key, "unused") {
+			unused = 1
+		}
+
+		localDisks = append(localDisks, map[string]any{
+			"volid":      volume,
+			"drivename":  key,
+			"size":       diskSize(value),
+			"cdrom":      cdrom,
+			"is_unused":  unused,
+			"replicated": 0,
+		})
+	}
+
+	allowed := []string{}
+	notAllowed := map[string]any{}
+
+	for _, node := range sortedKeys(s.nodes) {
+		if node == vm.Node || s.nodes[node].Status != "online" {
+			continue
+		}
+
+		unavailable := []string{}
+
+		for _, storage := range storages {
+			if _, ok := s.storages[storageKey(node, storage)]; !ok {
+				unavailable = append(unavailable, storage)
+			}
+		}
+
+		if len(unavailable) > 0 {
+			notAllowed[node] = map[string]any{"unavailable_storages": unavailable}
+
+			continue
+		}
+
+		allowed = append(allowed, node)
+	}
+
+	local, mapped := localResources(vm)
+
+	res := map[string]any{
+		"running":          running,
+		"local_disks":      localDisks,
+		"local_resources":  local,
+		"mapped-resources": mapped,
+	}
+
+	// Proxmox checks the target nodes only for a stopped VM.
+	if running == 0 {
+		res["allowed_nodes"] = allowed
+		res["not_allowed_nodes"] = notAllowed
+	}
+
+	return res, nil
+}
+
+// localResources returns the host devices of the VM, and the devices mapped cluster-wide.
+func localResources(vm *VM) (local []string, mapped []string) {
+	local, mapped = []string{}, []string{}
+
+	for _, key := range sortedKeys(vm.Config) {
+		if !hostDevRegexp.MatchString(key) {
+			continue
+		}
+
+		if strings.Contains(paramString(vm.Config, key), "mapping=") {
+			mapped = append(mapped, key)
+		} else {
+			local = append(local, key)
+		}
+	}
+
+	return local, mapped
+}
+
+// mapStorage returns the target of the storage or the bridge in the mapping,
+// e.g. "local-lvm:local-zfs,rbd" maps local-lvm to local-zfs and the other storages to rbd.
+func mapStorage(mapping, storage string) string {
+	target := storage
+
+	for _, pair := range strings.Split(mapping, ",") {
+		src, dst, ok := strings.Cut(pair, ":")
+		switch {
+		case ok && src == storage:
+			return dst
+		case !ok && pair != "" && pair != "1":
+			target = pair
+		}
+	}
+
+	return target
+}
+
+// moveGuest moves the VM and its local disks to the target node.
+func (s *Server) moveGuest(vm *VM, target string, moves map[string]*Storage) {
+	for key, dst := range moves {
+		value := paramString(vm.Config, key)
+		volume := diskVolume(value)
+		storage, name, _ := strings.Cut(volume, ":")
+
+		src := s.storages[storageKey(vm.Node, storage)]
+		v := src.Volumes[volume]
+		delete(src.Volumes, volume)
+
+		volid := dst.Storage + ":" + name
+		if v == nil {
+			v = &Volume{VMID: vm.VMID, Format: "raw", Content: "images"}
+		}
+
+		v.VolID = volid
+		dst.Volumes[volid] = v
+
+		vm.Config[key] = volid + strings.TrimPrefix(value, volume)
+	}
+
+	vm.Node = target
+}
+
+func (s *Server) resizeVMDisk(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.resizeDisk(vm, params)
+}
+
+// resizeDisk grows the disk of the VM or the container.
+func (s *Server) resizeDisk(vm *VM, params map[string]any) (any, error) {
+	if err := checkLock(vm, params); err != nil {
+		return nil, err
+	}
+
+	disk := paramString(params, "disk")
+
+	value := paramString(vm.Config, disk)
+	if value == "" {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("disk '%s' does not exist", disk)}
+	}
+
+	volume := diskVolume(value)
+	current := diskSize(value)
+
+	if st, ok := s.storages[storageKey(vm.Node, strings.Split(volume, ":")[0])]; ok {
+		if v, ok := st.Volumes[volume]; ok && v.Size > 0 {
+			current = v.Size
+		}
+	}
+
+	size := paramString(params, "size")
+
+	newSize, err := parseSize(strings.TrimPrefix(size, "+"), 1)
+	if err != nil {
+		return nil, badRequest("size", err.Error())
+	}
+
+	if strings.HasPrefix(size, "+") {
+		newSize += current
+	}
+
+	if newSize < current {
+		return nil, &apiError{status: http.StatusInternalServerError, message: "shrinking disks is not supported"}
+	}
+
+	if st, ok := s.storages[storageKey(vm.Node, strings.Split(volume, ":")[0])]; ok {
+		if v, ok := st.Volumes[volume]; ok {
+			v.Size = newSize
+		}
+	}
+
+	vm.Config[disk] = setDiskOption(value, "size", formatSize(newSize))
+
+	return s.newTask(vm.Node, "resize", strconv.Itoa(vm.VMID), 0, "").UPID, nil
+}
+
+func (s *Server) unlinkVMDisk(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLock(vm, params); err != nil {
+		return nil, err
+	}
+
+	force := paramBool(params, "force")
+
+	for _, key := range strings.Split(paramString(params, "idlist"), ",") {
+		value := paramString(vm.Config, key)
+		if value == "" {
+			continue
+		}
+
+		delete(vm.Config, key)
+
+		if force || strings.HasPrefix(key, "unused") {
+			s.deleteVolume(vm.Node, diskVolume(value))
+
+			continue
+		}
+
+		for i := 0; ; i++ {
+			unused := fmt.Sprintf("unused%d", i)
+			if _, ok := vm.Config[unused]; !ok {
+				vm.Config[unused] = diskVolume(value)
+
+				break
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *Server) moveVMDisk(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	disk := paramString(params, "disk")
+	value := paramString(vm.Config, disk)
+
+	if !diskKeyRegexp.MatchString(disk) || strings.HasPrefix(disk, "unused") || value == "" {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("disk '%s' does not exist", disk)}
+	}
+
+	st, err := s.lookupStorage(vm.Node, paramString(params, "storage"))
+	if err != nil {
+		return nil, err
+	}
+
+	volume := diskVolume(value)
+	if storage, _, _ := strings.Cut(volume, ":"); storage == st.Storage {
+		return nil, &apiError{status: http.StatusInternalServerError, message: "you can't move to the same storage with same format"}
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	size := diskSize(value)
+	volid := st.Storage + ":" + s.nextDiskName(st, vm.VMID)
+
+	task := s.newTaskWithResult(vm.Node, "qmmove", strconv.Itoa(vm.VMID), vm.VMID, "disk", func() {
+		st.Volumes[volid] = &Volume{VolID: volid, VMID: vm.VMID, Size: size, Format: "raw", Content: "images"}
+		vm.Config[disk] = volid + strings.TrimPrefix(value, volume)
+
+		if paramBool(params, "delete") {
+			s.deleteVolume(vm.Node, volume)
+		} else {
+			vm.Config[nextUnusedKey(vm)] = volume
+		}
+	}, fmt.Sprintf("create full clone of drive %s (%s)", disk, volume))
+
+	return task.UPID, nil
+}
+
+func (s *Server) regenerateCloudInit(r *http.Request, _ map[string]any) (any, error) {
+	if _, err := s.lookupVMRequest(r); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (s *Server) lookupVMRequest(r *http.Request) (*VM, error) {
+	vmid, err := pathVMID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.lookupVM(r.PathValue("node"), vmid)
+}
+
+// allocateDisks allocates new volumes for disks defined as "<storage>:<size in GiB>".
+func (s *Server) allocateDisks(vm *VM) error {
+	for _, key := range sortedKeys(vm.Config) {
+		if !isDiskKey(vm, key) {
+			continue
+		}
+
+		value := paramString(vm.Config, key)
+
+		m := newDiskRegexp.FindStringSubmatch(diskVolume(value))
+		if m == nil {
+			continue
+		}
+
+		st, err := s.lookupStorage(vm.Node, m[1])
+		if err != nil {
+			return err
+		}
+
+		size, err := parseSize(m[2], 1<<30)
+		if err != nil {
+			return badRequest(key, err.Error())
+		}
+
+		content := "images"
+		if vm.Type == "lxc" {
+			content = "rootdir"
+		}
+
+		volid := st.Storage + ":" + s.nextDiskName(st, vm.VMID)
+		st.Volumes[volid] = &Volume{VolID: volid, VMID: vm.VMID, Size: size, Format: "raw", Content: content}
+
+		vm.Config[key] = setDiskOption(volid+strings.TrimPrefix(value, diskVolume(value)), "size", formatSize(size))
+	}
+
+	return nil
+}
+
+func (s *Server) nextDiskName(st *Storage, vmid int) string {
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("vm-%d-disk-%d", vmid, i)
+		if _, ok := st.Volumes[st.Storage+":"+name]; !ok {
+			return name
+		}
+	}
+}
+
+func (s *Server) deleteVolume(node, volume string) {
+	storage, _, _ := strings.Cut(volume, ":")
+
+	if st, ok := s.storages[storageKey(node, storage)]; ok {
+		delete(st.Volumes, volume)
+	}
+}
+
+// isDiskKey reports whether the config key of the VM or the container is a disk.
+func isDiskKey(vm *VM, key string) bool {
+	if vm.Type == "lxc" {
+		return ctDiskKeyRegexp.MatchString(key)
+	}
+
+	return diskKeyRegexp.MatchString(key)
+}
+
+func checkLock(vm *VM, params map[string]any) error {
+	if lock := paramString(vm.Config, "lock"); lock != "" && !paramBool(params, "skiplock") {
+		return &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("VM is locked (%s)", lock)}
+	}
+
+	return nil
+}
+
+func normalizeConfig(config map[string]any) map[string]any {
+	res := make(map[string]any, len(config))
+
+	for k, v := range config {
+		switch val := v.(type) {
+		case float64:
+			if val == float64(int64(val)) {
+				v = int(val)
+			}
+		case bool:
+			v = 0
+			if val {
+				v = 1
+			}
+		case string:
+			for _, key := range intConfigKeys {
+				if k == key {
+					if i, err := strconv.Atoi(val); err == nil {
+						v = i
+					}
+				}
+			}
+		}
+
+		res[k] = v
+	}
+
+	return res
+}
+
+func configDigest(config map[string]any) string {
+	data, _ := json.Marshal(config) //nolint:errcheck
+
+	return fmt.Sprintf("%x", sha1.Sum(data)) //nolint:gosec
+}
+
+func diskVolume(value string) string {
+	volume, _, _ := strings.Cut(value, ",")
+
+	return volume
+}
+
+func diskSize(value string) int64 {
+	for _, opt := range strings.Split(value, ",")[1:] {
+		if v, ok := strings.CutPrefix(opt, "size="); ok {
+			if size, err := parseSize(v, 1); err == nil {
+				return size
+			}
+		}
+	}
+
+	return 0
+}
+
+func setDiskOption(value, key, option string) string {
+	parts := strings.Split(value, ",")
+
+	for i, opt := range parts[1:] {
+		if strings.HasPrefix(opt, key+"=") {
+			parts[i+1] = key + "=" + option
+
+			return strings.Join(parts, ",")
+		}
+	}
+
+	return value + "," + key + "=" + option
+}
+
+func regenerateUUID(smbios string) string {
+	b := make([]byte, 16)
+	rand.Read(b) //nolint:errcheck
+
+	uuid := fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+
+	parts := strings.Split(smbios, ",")
+	for i, opt := range parts {
+		if strings.HasPrefix(opt, "uuid=") {
+			parts[i] = "uuid=" + uuid
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
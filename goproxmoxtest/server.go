@@ -0,0 +1,574 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmoxtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIPath is the path prefix of the Proxmox VE JSON API.
+const APIPath = "/api2/json"
+
+// Server is an in-memory Proxmox VE API server.
+type Server struct {
+	srv *httptest.Server
+
+	// TaskDuration is the time a task stays in the running state before it completes.
+	// Zero means tasks complete immediately.
+	TaskDuration time.Duration
+	// Latency is the delay added to every API response.
+	Latency time.Duration
+
+	mu       sync.Mutex
+	nodes    map[string]*Node
+	storages map[string]*Storage
+	vms      map[int]*VM
+	pools    map[string]string
+	tasks    map[string]*Task
+	taskList []*Task
+	failures map[string]string
+	requests map[string]int
+	tokens   map[string][]string
+	pid      int
+
+	nextIDLower int
+	nextIDUpper int
+}
+
+// Node is a cluster node of the fake server.
+type Node struct {
+	Name   string
+	Status string
+}
+
+// Storage is a storage of the fake server.
+type Storage struct {
+	Node    string
+	Storage string
+	Type    string
+	Content string
+	Shared  bool
+	Volumes map[string]*Volume
+}
+
+// Volume is a storage volume of the fake server.
+type Volume struct {
+	VolID   string
+	VMID    int
+	Size    int64
+	Format  string
+	Content string
+	Notes   string
+	CTime   int64
+	// Config is the guest configuration stored in a backup archive.
+	Config map[string]any
+}
+
+// VM is a virtual machine or a container of the fake server.
+type VM struct {
+	Node      string
+	VMID      int
+	Type      string // qemu or lxc
+	Status    string
+	QMPStatus string
+	Config    map[string]any
+	Snapshots map[string]*Snapshot
+}
+
+// NewServer starts a new fake Proxmox VE API server.
+func NewServer() *Server {
+	s := &Server{
+		nodes:    map[string]*Node{},
+		storages: map[string]*Storage{},
+		vms:      map[int]*VM{},
+		pools:    map[string]string{},
+		tasks:    map[string]*Task{},
+		failures: map[string]string{},
+		requests: map[string]int{},
+		tokens:   map[string][]string{},
+		pid:      0x1000,
+	}
+
+	mux := http.NewServeMux()
+	s.registerClusterRoutes(mux)
+	s.registerStorageRoutes(mux)
+	s.registerQemuRoutes(mux)
+	s.registerLXCRoutes(mux)
+	s.registerSnapshotRoutes(mux)
+	s.registerBackupRoutes(mux)
+	s.registerPoolRoutes(mux)
+	s.registerTaskRoutes(mux)
+
+	s.srv = httptest.NewServer(mux)
+
+	return s
+}
+
+// URL returns the base API URL to be used with goproxmox.NewAPIClient.
+func (s *Server) URL() string {
+	return s.srv.URL + APIPath
+}
+
+// SetNextIDRange sets the next-id bounds of the cluster options, the upper bound is exclusive.
+func (s *Server) SetNextIDRange(lower, upper int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextIDLower = lower
+	s.nextIDUpper = upper
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// AddNode adds an online node to the cluster.
+func (s *Server) AddNode(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nodes[name] = &Node{Name: name, Status: "online"}
+}
+
+// SetNodeStatus changes the status of the node, e.g. "offline".
+func (s *Server) SetNodeStatus(name, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n, ok := s.nodes[name]; ok {
+		n.Status = status
+	}
+}
+
+// AddStorage adds a storage to the node.
+// Shared storages have to be added to every node they are available on.
+func (s *Server) AddStorage(node, storage string, shared bool) {
+	typ := "lvmthin"
+	if shared {
+		typ = "rbd"
+	}
+
+	s.addStorage(node, storage, typ, "images,rootdir", shared)
+}
+
+// AddBackupStorage adds a storage for backup archives to the node.
+// Shared storages have to be added to every node they are available on.
+func (s *Server) AddBackupStorage(node, storage string, shared bool) {
+	typ := "dir"
+	if shared {
+		typ = "nfs"
+	}
+
+	s.addStorage(node, storage, typ, "backup,iso,vztmpl", shared)
+}
+
+func (s *Server) addStorage(node, storage, typ, content string, shared bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	volumes := map[string]*Volume{}
+
+	// Shared storages have the same content on every node.
+	for _, st := range s.storages {
+		if shared && st.Shared && st.Storage == storage {
+			volumes = st.Volumes
+
+			break
+		}
+	}
+
+	s.storages[storageKey(node, storage)] = &Storage{
+		Node:    node,
+		Storage: storage,
+		Type:    typ,
+		Content: content,
+		Shared:  shared,
+		Volumes: volumes,
+	}
+}
+
+// AddVM adds a stopped virtual machine with the given configuration.
+func (s *Server) AddVM(node string, vmid int, config map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.vms[vmid] = &VM{
+		Node:   node,
+		VMID:   vmid,
+		Type:   "qemu",
+		Status: "stopped",
+		Config: normalizeConfig(config),
+	}
+}
+
+// AddTemplate adds a virtual machine template with the given configuration.
+func (s *Server) AddTemplate(node string, vmid int, config map[string]any) {
+	cfg := maps.Clone(config)
+	if cfg == nil {
+		cfg = map[string]any{}
+	}
+
+	cfg["template"] = 1
+
+	s.AddVM(node, vmid, cfg)
+}
+
+// VM returns a copy of the virtual machine state.
+func (s *Server) VM(vmid int) (VM, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshTasks()
+
+	vm, ok := s.vms[vmid]
+	if !ok {
+		return VM{}, false
+	}
+
+	res := *vm
+	res.Config = maps.Clone(vm.Config)
+	res.Snapshots = maps.Clone(vm.Snapshots)
+
+	return res, true
+}
+
+// Volumes returns the volume IDs of the storage on the node.
+func (s *Server) Volumes(node, storage string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.storages[storageKey(node, storage)]
+	if !ok {
+		return nil
+	}
+
+	return sortedKeys(st.Volumes)
+}
+
+// RequestCount returns the number of requests with the method to the API path, e.g. "/cluster/resources".
+func (s *Server) RequestCount(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[method+" "+path]
+}
+
+// FailTask makes the next task of the given type (e.g. "qmclone") fail with the exit status.
+func (s *Server) FailTask(taskType, exitStatus string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failures[taskType] = exitStatus
+}
+
+// AddAPIToken adds an API token, e.g. "user@pve!token", with the privileges on the root path, e.g. "Sys.Audit".
+// The token has all the other privileges, requests without a token have all of them.
+func (s *Server) AddAPIToken(tokenID string, privileges ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[tokenID] = privileges
+}
+
+// apiToken returns the token ID of the request, or an empty string for requests without a token.
+func apiToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "PVEAPIToken=")
+	if !ok {
+		return ""
+	}
+
+	if i := strings.LastIndex(token, "="); i >= 0 {
+		token = token[:i]
+	}
+
+	return token
+}
+
+// checkPrivilege returns a permission error if the API token of the request lacks the privilege on the root path.
+func (s *Server) checkPrivilege(r *http.Request, privilege string) error {
+	token := apiToken(r)
+	if token == "" || slices.Contains(s.tokens[token], privilege) {
+		return nil
+	}
+
+	return &apiError{status: http.StatusForbidden, message: fmt.Sprintf("Permission check failed (/, %s)", privilege)}
+}
+
+func (s *Server) lookupVM(node string, vmid int) (*VM, error) {
+	return s.lookupGuest(node, "qemu", vmid)
+}
+
+// lookupGuest returns the VM or the container of the type on the node.
+func (s *Server) lookupGuest(node, typ string, vmid int) (*VM, error) {
+	if err := s.checkNode(node); err != nil {
+		return nil, err
+	}
+
+	vm, ok := s.vms[vmid]
+	if !ok || vm.Node != node || vm.Type != typ {
+		dir := "qemu-server"
+		if typ == "lxc" {
+			dir = "lxc"
+		}
+
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("Configuration file 'nodes/%s/%s/%d.conf' does not exist", node, dir, vmid),
+		}
+	}
+
+	return vm, nil
+}
+
+func (s *Server) checkNode(node string) error {
+	n, ok := s.nodes[node]
+	if !ok {
+		return &apiError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("hostname lookup '%s' failed - failed to get address info for: %s: Name or service not known", node, node),
+		}
+	}
+
+	if n.Status != "online" {
+		return &apiError{status: 595, message: "No route to host"}
+	}
+
+	return nil
+}
+
+func (s *Server) lookupStorage(node, storage string) (*Storage, error) {
+	if err := s.checkNode(node); err != nil {
+		return nil, err
+	}
+
+	st, ok := s.storages[storageKey(node, storage)]
+	if !ok {
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("storage '%s' does not exist", storage),
+		}
+	}
+
+	return st, nil
+}
+
+type apiError struct {
+	status  int
+	message string
+	errors  map[string]string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func badRequest(field, message string) *apiError {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		message: "Parameter verification failed.",
+		errors:  map[string]string{field: message},
+	}
+}
+
+type handlerFunc func(r *http.Request, params map[string]any) (any, error)
+
+// handle wraps the handler with locking, request parsing and the Proxmox response envelope.
+func (s *Server) handle(fn handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params, err := parseParams(r)
+		if err != nil {
+			writeError(w, &apiError{status: http.StatusBadRequest, message: err.Error()})
+
+			return
+		}
+
+		time.Sleep(s.Latency)
+
+		s.mu.Lock()
+		s.requests[r.Method+" "+strings.TrimPrefix(r.URL.Path, APIPath)]++
+
+		if token := apiToken(r); token != "" {
+			if _, ok := s.tokens[token]; !ok {
+				s.mu.Unlock()
+				writeError(w, &apiError{status: http.StatusUnauthorized, message: "invalid token value!"})
+
+				return
+			}
+		}
+
+		s.refreshTasks()
+		data, err := fn(r, params)
+		s.mu.Unlock()
+
+		if err != nil {
+			apiErr, ok := err.(*apiError)
+			if !ok {
+				apiErr = &apiError{status: http.StatusInternalServerError, message: err.Error()}
+			}
+
+			writeError(w, apiErr)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+		json.NewEncoder(w).Encode(map[string]any{"data": data}) //nolint:errcheck
+	}
+}
+
+// writeError writes the error response the same way pveproxy does,
+// the error message is sent as the HTTP reason phrase.
+func writeError(w http.ResponseWriter, e *apiError) {
+	body := map[string]any{"data": nil, "message": e.message}
+	if len(e.errors) > 0 {
+		body["errors"] = e.errors
+	}
+
+	data, _ := json.Marshal(body) //nolint:errcheck
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+		w.WriteHeader(e.status)
+		w.Write(data) //nolint:errcheck
+
+		return
+	}
+
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	writeRawResponse(buf.Writer, e.status, strings.ReplaceAll(e.message, "\n", " "), data)
+}
+
+func writeRawResponse(w *bufio.Writer, status int, reason string, body []byte) {
+	fmt.Fprintf(w, "HTTP/1.1 %d %s\r\n", status, reason)
+	fmt.Fprintf(w, "Content-Type: application/json;charset=UTF-8\r\n")
+	fmt.Fprintf(w, "Content-Length: %d\r\n", len(body))
+	fmt.Fprintf(w, "Connection: close\r\n\r\n")
+	w.Write(body) //nolint:errcheck
+	w.Flush()     //nolint:errcheck
+}
+
+func parseParams(r *http.Request) (map[string]any, error) {
+	params := map[string]any{}
+
+	for k, v := range r.URL.Query() {
+		params[k] = v[0]
+	}
+
+	if r.Body == nil || r.ContentLength == 0 {
+		return params, nil
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		body := map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, fmt.Errorf("failed to decode request body: %w", err)
+		}
+
+		maps.Copy(params, body)
+
+		return params, nil
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	for k, v := range r.PostForm {
+		params[k] = v[0]
+	}
+
+	return params, nil
+}
+
+func pathVMID(r *http.Request) (int, error) {
+	vmid, err := strconv.Atoi(r.PathValue("vmid"))
+	if err != nil {
+		return 0, badRequest("vmid", "type check ('integer') failed")
+	}
+
+	return vmid, nil
+}
+
+func paramString(params map[string]any, key string) string {
+	switch v := params[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+
+		return "0"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func paramInt(params map[string]any, key string) (int, bool) {
+	v := paramString(params, key)
+	if v == "" {
+		return 0, false
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+
+	return i, true
+}
+
+func paramBool(params map[string]any, key string) bool {
+	v := paramString(params, key)
+
+	return v == "1" || v == "true"
+}
+
+func storageKey(node, storage string) string {
+	return node + "/" + storage
+}
+
+func unescape(s string) string {
+	if u, err := url.PathUnescape(s); err == nil {
+		return u
+	}
+
+	return s
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmoxtest
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Snapshot is a snapshot of a virtual machine.
+type Snapshot struct {
+	Name        string
+	Description string
+	Parent      string
+	SnapTime    int64
+	VMState     bool
+	Config      map[string]any
+}
+
+func (s *Server) registerSnapshotRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/qemu/{vmid}/snapshot", s.handle(s.listSnapshots))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu/{vmid}/snapshot", s.handle(s.createSnapshot))
+	mux.HandleFunc("DELETE "+APIPath+"/nodes/{node}/qemu/{vmid}/snapshot/{snapname}", s.handle(s.deleteSnapshot))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/qemu/{vmid}/snapshot/{snapname}/rollback", s.handle(s.rollbackSnapshot))
+}
+
+func (s *Server) listSnapshots(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []map[string]any{}
+
+	for _, name := range sortedKeys(vm.Snapshots) {
+		snap := vm.Snapshots[name]
+
+		item := map[string]any{
+			"name":        snap.Name,
+			"description": snap.Description,
+			"snaptime":    snap.SnapTime,
+		}
+
+		if snap.Parent != "" {
+			item["parent"] = snap.Parent
+		}
+
+		if snap.VMState {
+			item["vmstate"] = 1
+		}
+
+		res = append(res, item)
+	}
+
+	current := map[string]any{
+		"name":        "current",
+		"description": "You are here!",
+		"running":     0,
+	}
+
+	if vm.Status == "running" {
+		current["running"] = 1
+	}
+
+	if parent := paramString(vm.Config, "parent"); parent != "" {
+		current["parent"] = parent
+	}
+
+	return append(res, current), nil
+}
+
+func (s *Server) createSnapshot(r *http.Request, params map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	name := paramString(params, "snapname")
+	if name == "" {
+		return nil, badRequest("snapname", "property is missing and it is not optional")
+	}
+
+	if name == "current" {
+		return nil, badRequest("snapname", "invalid format - invalid configuration ID 'current'")
+	}
+
+	if _, ok := vm.Snapshots[name]; ok {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("snapshot name '%s' already used", name)}
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	snap := &Snapshot{
+		Name:        name,
+		Description: paramString(params, "description"),
+		Parent:      paramString(vm.Config, "parent"),
+		SnapTime:    time.Now().Unix(),
+		VMState:     paramBool(params, "vmstate") && vm.Status == "running",
+		Config:      maps.Clone(vm.Config),
+	}
+
+	task := s.newTaskWithResult(vm.Node, "qmsnapshot", strconv.Itoa(vm.VMID), vm.VMID, "snapshot", func() {
+		if vm.Snapshots == nil {
+			vm.Snapshots = map[string]*Snapshot{}
+		}
+
+		delete(snap.Config, "lock")
+
+		vm.Snapshots[name] = snap
+		vm.Config["parent"] = name
+	})
+
+	return task.UPID, nil
+}
+
+func (s *Server) rollbackSnapshot(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := lookupSnapshot(vm, r.PathValue("snapname"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	task := s.newTaskWithResult(vm.Node, "qmrollback", strconv.Itoa(vm.VMID), vm.VMID, "rollback", func() {
+		vm.Config = maps.Clone(snap.Config)
+		vm.Config["parent"] = snap.Name
+
+		vm.Status, vm.QMPStatus = "stopped", "stopped"
+		if snap.VMState {
+			vm.Status, vm.QMPStatus = "running", "running"
+		}
+	})
+
+	return task.UPID, nil
+}
+
+func (s *Server) deleteSnapshot(r *http.Request, _ map[string]any) (any, error) {
+	vm, err := s.lookupVMRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := lookupSnapshot(vm, r.PathValue("snapname"))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkLock(vm, nil); err != nil {
+		return nil, err
+	}
+
+	task := s.newTaskWithResult(vm.Node, "qmdelsnapshot", strconv.Itoa(vm.VMID), vm.VMID, "snapshot-delete", func() {
+		delete(vm.Snapshots, snap.Name)
+
+		for _, child := range vm.Snapshots {
+			if child.Parent == snap.Name {
+				child.Parent = snap.Parent
+			}
+		}
+
+		if paramString(vm.Config, "parent") == snap.Name {
+			vm.Config["parent"] = snap.Parent
+			if snap.Parent == "" {
+				delete(vm.Config, "parent")
+			}
+		}
+	})
+
+	return task.UPID, nil
+}
+
+func lookupSnapshot(vm *VM, name string) (*Snapshot, error) {
+	snap, ok := vm.Snapshots[name]
+	if !ok {
+		return nil, &apiError{status: http.StatusInternalServerError, message: fmt.Sprintf("snapshot '%s' does not exist", name)}
+	}
+
+	return snap, nil
+}
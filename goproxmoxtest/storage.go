@@ -0,0 +1,206 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmoxtest
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var sizeRegexp = regexp.MustCompile(`^(\d+(?:\.\d+)?)([KMGT]?)$`)
+
+func (s *Server) registerStorageRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/storage/{storage}/status", s.handle(s.getStorageStatus))
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/storage/{storage}/content", s.handle(s.getStorageContent))
+	mux.HandleFunc("POST "+APIPath+"/nodes/{node}/storage/{storage}/content", s.handle(s.createStorageVolume))
+	mux.HandleFunc("DELETE "+APIPath+"/nodes/{node}/storage/{storage}/content/{volume...}", s.handle(s.deleteStorageVolume))
+}
+
+func (s *Server) getStorageStatus(r *http.Request, _ map[string]any) (any, error) {
+	st, err := s.lookupStorage(r.PathValue("node"), r.PathValue("storage"))
+	if err != nil {
+		return nil, err
+	}
+
+	var used int64
+	for _, v := range st.Volumes {
+		used += v.Size
+	}
+
+	shared := 0
+	if st.Shared {
+		shared = 1
+	}
+
+	return map[string]any{
+		"storage": st.Storage,
+		"type":    st.Type,
+		"content": st.Content,
+		"shared":  shared,
+		"enabled": 1,
+		"active":  1,
+		"total":   1 << 40,
+		"used":    used,
+		"avail":   1<<40 - used,
+	}, nil
+}
+
+func (s *Server) getStorageContent(r *http.Request, params map[string]any) (any, error) {
+	st, err := s.lookupStorage(r.PathValue("node"), r.PathValue("storage"))
+	if err != nil {
+		return nil, err
+	}
+
+	content := paramString(params, "content")
+	vmid, filterVMID := paramInt(params, "vmid")
+
+	res := []map[string]any{}
+
+	for _, volid := range sortedKeys(st.Volumes) {
+		v := st.Volumes[volid]
+
+		if content != "" && v.Content != content {
+			continue
+		}
+
+		if filterVMID && v.VMID != vmid {
+			continue
+		}
+
+		item := map[string]any{
+			"volid":   v.VolID,
+			"vmid":    v.VMID,
+			"size":    v.Size,
+			"format":  v.Format,
+			"content": v.Content,
+		}
+
+		if v.Notes != "" {
+			item["notes"] = v.Notes
+		}
+
+		if v.CTime != 0 {
+			item["ctime"] = v.CTime
+		}
+
+		res = append(res, item)
+	}
+
+	return res, nil
+}
+
+func (s *Server) createStorageVolume(r *http.Request, params map[string]any) (any, error) {
+	st, err := s.lookupStorage(r.PathValue("node"), r.PathValue("storage"))
+	if err != nil {
+		return nil, err
+	}
+
+	vmid, ok := paramInt(params, "vmid")
+	if !ok {
+		return nil, badRequest("vmid", "property is missing and it is not optional")
+	}
+
+	filename := paramString(params, "filename")
+	if filename == "" {
+		return nil, badRequest("filename", "property is missing and it is not optional")
+	}
+
+	size, err := parseSize(paramString(params, "size"), 1<<10)
+	if err != nil {
+		return nil, badRequest("size", err.Error())
+	}
+
+	volid := st.Storage + ":" + filename
+	if _, ok := st.Volumes[volid]; ok {
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("volume '%s' already exists", volid),
+		}
+	}
+
+	st.Volumes[volid] = &Volume{VolID: volid, VMID: vmid, Size: size, Format: "raw", Content: "images"}
+
+	return volid, nil
+}
+
+func (s *Server) deleteStorageVolume(r *http.Request, _ map[string]any) (any, error) {
+	node := r.PathValue("node")
+
+	st, err := s.lookupStorage(node, r.PathValue("storage"))
+	if err != nil {
+		return nil, err
+	}
+
+	volid := unescape(r.PathValue("volume"))
+	if !strings.Contains(volid, ":") {
+		volid = st.Storage + ":" + volid
+	}
+
+	if _, ok := st.Volumes[volid]; !ok {
+		return nil, &apiError{
+			status:  http.StatusInternalServerError,
+			message: fmt.Sprintf("no such volume '%s'", volid),
+		}
+	}
+
+	delete(st.Volumes, volid)
+
+	return s.newTask(node, "imgdel", volid, 0, "").UPID, nil
+}
+
+// parseSize parses sizes like "10G" or "512M", values without a unit are multiplied by unit.
+func parseSize(size string, unit int64) (int64, error) {
+	m := sizeRegexp.FindStringSubmatch(strings.TrimSpace(size))
+	if m == nil {
+		return 0, fmt.Errorf("value '%s' does not look like a valid disk size", size)
+	}
+
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	switch m[2] {
+	case "K":
+		unit = 1 << 10
+	case "M":
+		unit = 1 << 20
+	case "G":
+		unit = 1 << 30
+	case "T":
+		unit = 1 << 40
+	}
+
+	return int64(v * float64(unit)), nil
+}
+
+// formatSize formats the size in bytes the same way qemu-server does.
+func formatSize(size int64) string {
+	switch {
+	case size%(1<<40) == 0:
+		return fmt.Sprintf("%dT", size>>40)
+	case size%(1<<30) == 0:
+		return fmt.Sprintf("%dG", size>>30)
+	case size%(1<<20) == 0:
+		return fmt.Sprintf("%dM", size>>20)
+	default:
+		return fmt.Sprintf("%dK", size>>10)
+	}
+}
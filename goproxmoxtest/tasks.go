@@ -0,0 +1,321 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmoxtest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Task is a Proxmox task of the fake server.
+type Task struct {
+	UPID       string
+	Node       string
+	Type       string
+	ID         string
+	User       string
+	Status     string
+	ExitStatus string
+	StartTime  time.Time
+	EndTime    time.Time
+	Log        []string
+
+	endAt     time.Time
+	lockVMID  int
+	onSuccess func()
+}
+
+// Tasks returns a copy of all tasks in creation order.
+func (s *Server) Tasks() []Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refreshTasks()
+
+	res := make([]Task, 0, len(s.taskList))
+	for _, t := range s.taskList {
+		res = append(res, *t)
+	}
+
+	return res
+}
+
+// newTask creates a new task with the given log lines.
+// The VM lockVMID is locked with lock until the task completes.
+func (s *Server) newTask(node, typ, id string, lockVMID int, lock string, log ...string) *Task {
+	return s.newTaskWithResult(node, typ, id, lockVMID, lock, nil, log...)
+}
+
+// newTaskWithResult creates a new task which calls onSuccess if it completes successfully.
+func (s *Server) newTaskWithResult(node, typ, id string, lockVMID int, lock string, onSuccess func(), log ...string) *Task {
+	s.pid++
+
+	now := time.Now()
+	upid := fmt.Sprintf("UPID:%s:%08X:%08X:%08X:%s:%s:root@pam:", node, s.pid, s.pid*7, now.Unix(), typ, id)
+
+	t := &Task{
+		UPID:      upid,
+		Node:      node,
+		Type:      typ,
+		ID:        id,
+		User:      "root@pam",
+		Status:    "running",
+		StartTime: now,
+		Log:       log,
+		endAt:     now.Add(s.TaskDuration),
+		onSuccess: onSuccess,
+	}
+
+	if exitStatus, ok := s.failures[typ]; ok {
+		t.ExitStatus = exitStatus
+		delete(s.failures, typ)
+	}
+
+	if vm, ok := s.vms[lockVMID]; ok && lock != "" {
+		vm.Config["lock"] = lock
+		t.lockVMID = lockVMID
+	}
+
+	s.tasks[upid] = t
+	s.taskList = append(s.taskList, t)
+
+	if s.TaskDuration == 0 {
+		s.completeTask(t, t.ExitStatus)
+	}
+
+	return t
+}
+
+func (s *Server) refreshTasks() {
+	now := time.Now()
+
+	for _, t := range s.taskList {
+		if t.Status == "running" && !now.Before(t.endAt) {
+			s.completeTask(t, t.ExitStatus)
+		}
+	}
+}
+
+func (s *Server) completeTask(t *Task, exitStatus string) {
+	if exitStatus == "" {
+		exitStatus = "OK"
+	}
+
+	t.Status = "stopped"
+	t.ExitStatus = exitStatus
+	t.EndTime = time.Now()
+
+	if exitStatus == "OK" {
+		if t.onSuccess != nil {
+			t.onSuccess()
+		}
+
+		t.Log = append(t.Log, "TASK OK")
+	} else {
+		t.Log = append(t.Log, "TASK ERROR: "+exitStatus)
+	}
+
+	if vm, ok := s.vms[t.lockVMID]; ok && t.lockVMID != 0 {
+		delete(vm.Config, "lock")
+	}
+}
+
+func (s *Server) registerTaskRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/tasks", s.handle(s.getNodeTasks))
+	mux.HandleFunc("GET "+APIPath+"/cluster/tasks", s.handle(s.getClusterTasks))
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/tasks/{upid}/status", s.handle(s.getTaskStatus))
+	mux.HandleFunc("GET "+APIPath+"/nodes/{node}/tasks/{upid}/log", s.handle(s.getTaskLog))
+	mux.HandleFunc("DELETE "+APIPath+"/nodes/{node}/tasks/{upid}", s.handle(s.stopTask))
+}
+
+// getNodeTasks lists the tasks of the node, newest first.
+// Without the source parameter only the finished tasks are listed.
+func (s *Server) getNodeTasks(r *http.Request, params map[string]any) (any, error) {
+	node := r.PathValue("node")
+	if err := s.checkNode(node); err != nil {
+		return nil, err
+	}
+
+	vmid, _ := paramInt(params, "vmid")
+	typ := paramString(params, "typefilter")
+	since, _ := paramInt(params, "since")
+	until, _ := paramInt(params, "until")
+	start, _ := paramInt(params, "start")
+
+	limit, ok := paramInt(params, "limit")
+	if !ok || limit <= 0 {
+		limit = 50
+	}
+
+	source := paramString(params, "source")
+	if source == "" {
+		source = "archive"
+	}
+
+	tasks := []map[string]any{}
+
+	for _, t := range slices.Backward(s.taskList) {
+		switch {
+		case t.Node != node,
+			vmid != 0 && t.ID != strconv.Itoa(vmid),
+			typ != "" && t.Type != typ,
+			since != 0 && t.StartTime.Unix() < int64(since),
+			until != 0 && t.StartTime.Unix() > int64(until),
+			source == "active" && t.Status != "running",
+			source == "archive" && t.Status == "running":
+			continue
+		}
+
+		if start > 0 {
+			start--
+
+			continue
+		}
+
+		tasks = append(tasks, taskListEntry(t))
+		if len(tasks) == limit {
+			break
+		}
+	}
+
+	return tasks, nil
+}
+
+// getClusterTasks lists the recent tasks of all nodes, newest first.
+func (s *Server) getClusterTasks(_ *http.Request, _ map[string]any) (any, error) {
+	tasks := make([]map[string]any, 0, len(s.taskList))
+	for _, t := range slices.Backward(s.taskList) {
+		tasks = append(tasks, taskListEntry(t))
+	}
+
+	return tasks, nil
+}
+
+// taskListEntry returns the task as listed by the API, the status field is the exit status of a finished task.
+func taskListEntry(t *Task) map[string]any {
+	res := map[string]any{
+		"upid":      t.UPID,
+		"node":      t.Node,
+		"type":      t.Type,
+		"id":        t.ID,
+		"user":      t.User,
+		"starttime": t.StartTime.Unix(),
+	}
+
+	if t.Status == "stopped" {
+		res["status"] = t.ExitStatus
+		res["endtime"] = t.EndTime.Unix()
+	}
+
+	return res
+}
+
+func (s *Server) lookupTask(r *http.Request) (*Task, error) {
+	node := r.PathValue("node")
+	if err := s.checkNode(node); err != nil {
+		return nil, err
+	}
+
+	t, ok := s.tasks[unescape(r.PathValue("upid"))]
+	if !ok || t.Node != node {
+		return nil, &apiError{status: http.StatusInternalServerError, message: "no such task"}
+	}
+
+	return t, nil
+}
+
+func (s *Server) getTaskStatus(r *http.Request, _ map[string]any) (any, error) {
+	t, err := s.lookupTask(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return taskStatus(t), nil
+}
+
+func taskStatus(t *Task) map[string]any {
+	res := map[string]any{
+		"upid":      t.UPID,
+		"node":      t.Node,
+		"type":      t.Type,
+		"id":        t.ID,
+		"user":      t.User,
+		"status":    t.Status,
+		"starttime": t.StartTime.Unix(),
+	}
+
+	if t.Status == "stopped" {
+		res["exitstatus"] = t.ExitStatus
+		res["endtime"] = t.EndTime.Unix()
+	}
+
+	return res
+}
+
+func (s *Server) getTaskLog(r *http.Request, params map[string]any) (any, error) {
+	t, err := s.lookupTask(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := paramInt(params, "start")
+	limit, ok := paramInt(params, "limit")
+	if !ok || limit <= 0 {
+		limit = 50
+	}
+
+	// The log of a running task grows with its progress.
+	visible := len(t.Log)
+	if total := t.endAt.Sub(t.StartTime); t.Status == "running" && total > 0 {
+		visible = min(visible, 1+int(float64(visible-1)*float64(time.Since(t.StartTime))/float64(total)))
+	}
+
+	lines := []map[string]any{}
+
+	for i := start; i < visible && len(lines) < limit; i++ {
+		lines = append(lines, map[string]any{"n": i + 1, "t": t.Log[i]})
+	}
+
+	return lines, nil
+}
+
+func (s *Server) stopTask(r *http.Request, _ map[string]any) (any, error) {
+	t, err := s.lookupTask(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Status == "running" {
+		s.completeTask(t, "interrupted by signal")
+	}
+
+	return nil, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
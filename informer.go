@@ -0,0 +1,458 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+)
+
+// defaultInformerInterval is the list interval of a ResourceInformer created without one.
+const defaultInformerInterval = 30 * time.Second
+
+// ResourceEventType is the type of a cluster resource change.
+type ResourceEventType string
+
+const (
+	// ResourceAdded is sent when a resource appears in the cluster.
+	ResourceAdded ResourceEventType = "Added"
+	// ResourceUpdated is sent when the status or the configuration of a resource changes.
+	ResourceUpdated ResourceEventType = "Updated"
+	// ResourceDeleted is sent when a resource disappears from the cluster.
+	ResourceDeleted ResourceEventType = "Deleted"
+	// ResourceMigrated is sent when a VM or container moves to another node.
+	ResourceMigrated ResourceEventType = "Migrated"
+)
+
+// ResourceEvent is a change of a cluster resource.
+type ResourceEvent struct {
+	Type ResourceEventType
+	// Resource is the current state, or the last known state for ResourceDeleted.
+	Resource *proxmox.ClusterResource
+	// Old is the previous state for ResourceUpdated and ResourceMigrated.
+	Old *proxmox.ClusterResource
+}
+
+// ResourceInformer watches the cluster resources and keeps them in a local store.
+//
+// It lists the resources periodically, and every time the APIClient fetches them,
+// compares the result with the previous snapshot and delivers the changes to
+// the event handlers. While the informer runs, the Get*ByFilter functions of
+// the APIClient read the resources from its store.
+type ResourceInformer struct {
+	client   *APIClient
+	interval time.Duration
+	types    []string
+	store    *ResourceStore
+
+	mu          sync.Mutex
+	handlers    []eventHandler
+	nextHandler int
+	queue       []ResourceEvent
+	notify      chan struct{}
+	lastErr     error
+}
+
+type eventHandler struct {
+	id int
+	fn func(ResourceEvent)
+}
+
+// NewResourceInformer creates an informer for the resource types ("vm", "node", "storage"),
+// all of them if no type is given. The resources are listed every interval,
+// or every 30 seconds if the interval is not positive.
+func (c *APIClient) NewResourceInformer(interval time.Duration, types ...string) *ResourceInformer {
+	if len(types) == 0 {
+		types = []string{"vm", "node", "storage"}
+	}
+
+	if interval <= 0 {
+		interval = defaultInformerInterval
+	}
+
+	return &ResourceInformer{
+		client:   c,
+		interval: interval,
+		types:    types,
+		store:    newResourceStore(),
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// AddEventHandler registers the handler for resource events.
+// Handlers are called sequentially from the informer goroutine.
+func (i *ResourceInformer) AddEventHandler(handler func(ResourceEvent)) {
+	i.addEventHandler(handler)
+}
+
+func (i *ResourceInformer) addEventHandler(handler func(ResourceEvent)) int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.nextHandler++
+	i.handlers = append(i.handlers, eventHandler{id: i.nextHandler, fn: handler})
+
+	return i.nextHandler
+}
+
+func (i *ResourceInformer) removeEventHandler(id int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.handlers = slices.DeleteFunc(i.handlers, func(h eventHandler) bool { return h.id == id })
+}
+
+// Watch returns a channel which receives the resource events until the context is done.
+// The channel is closed when the context is done.
+//
+// The events the consumer does not receive yet are queued beyond the channel buffer of the size,
+// so a slow consumer does not delay the other event handlers.
+func (i *ResourceInformer) Watch(ctx context.Context, size int) <-chan ResourceEvent {
+	ch := make(chan ResourceEvent, size)
+	w := &resourceWatcher{notify: make(chan struct{}, 1)}
+	id := i.addEventHandler(w.push)
+
+	go func() {
+		defer close(ch)
+		defer i.removeEventHandler(id)
+
+		for {
+			ev, ok := w.pop()
+			if !ok {
+				select {
+				case <-ctx.Done():
+					return
+				case <-w.notify:
+				}
+
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- ev:
+			}
+		}
+	}()
+
+	return ch
+}
+
+// resourceWatcher queues the resource events of a Watch channel.
+type resourceWatcher struct {
+	mu     sync.Mutex
+	queue  []ResourceEvent
+	notify chan struct{}
+}
+
+func (w *resourceWatcher) push(ev ResourceEvent) {
+	w.mu.Lock()
+	w.queue = append(w.queue, ev)
+	w.mu.Unlock()
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (w *resourceWatcher) pop() (ResourceEvent, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.queue) == 0 {
+		return ResourceEvent{}, false
+	}
+
+	ev := w.queue[0]
+	w.queue = w.queue[1:]
+
+	return ev, true
+}
+
+// Store returns the local resource store of the informer.
+func (i *ResourceInformer) Store() *ResourceStore {
+	return i.store
+}
+
+// HasSynced returns true once all the resource types were listed.
+func (i *ResourceInformer) HasSynced() bool {
+	for _, typ := range i.types {
+		if !i.store.hasSynced(typ) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LastSyncError returns the error of the last periodic list, if any.
+func (i *ResourceInformer) LastSyncError() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.lastErr
+}
+
+// Run starts the informer and blocks until the context is done.
+// Only one informer can be attached to the APIClient at a time.
+func (i *ResourceInformer) Run(ctx context.Context) {
+	i.client.informer.Store(i)
+	defer i.client.informer.CompareAndSwap(i, nil)
+
+	go i.dispatch(ctx)
+
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		_ = i.Resync(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Resync lists the resources immediately and delivers the changes to the event handlers.
+func (i *ResourceInformer) Resync(ctx context.Context) error {
+	var lastErr error
+
+	for _, typ := range i.types {
+		if _, err := i.client.fetchResources(ctx, typ); err != nil {
+			lastErr = err
+		}
+	}
+
+	i.mu.Lock()
+	i.lastErr = lastErr
+	i.mu.Unlock()
+
+	return lastErr
+}
+
+// update replaces the snapshot of the resource type and queues the changes.
+// The placeholder VMs of VMIDAllocator are left out.
+func (i *ResourceInformer) update(name string, resources proxmox.ClusterResources) {
+	events := i.store.replace(name, slices.DeleteFunc(slices.Clone(resources), isVMIDPlaceholder))
+	if len(events) == 0 {
+		return
+	}
+
+	i.mu.Lock()
+	i.queue = append(i.queue, events...)
+	i.mu.Unlock()
+
+	select {
+	case i.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (i *ResourceInformer) dispatch(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-i.notify:
+		}
+
+		i.mu.Lock()
+		events := i.queue
+		handlers := slices.Clone(i.handlers)
+		i.queue = nil
+		i.mu.Unlock()
+
+		for _, ev := range events {
+			for _, h := range handlers {
+				h.fn(ev)
+			}
+		}
+	}
+}
+
+// ResourceStore is a local indexed store of cluster resources.
+type ResourceStore struct {
+	mu     sync.RWMutex
+	items  map[string]map[string]*proxmox.ClusterResource
+	vmids  map[uint64]*proxmox.ClusterResource
+	synced map[string]bool
+	dirty  map[string]bool
+}
+
+func newResourceStore() *ResourceStore {
+	return &ResourceStore{
+		items:  map[string]map[string]*proxmox.ClusterResource{},
+		vmids:  map[uint64]*proxmox.ClusterResource{},
+		synced: map[string]bool{},
+		dirty:  map[string]bool{},
+	}
+}
+
+// Get returns the resource by its ID, e.g. "qemu/100" or "node/pve-1".
+func (s *ResourceStore) Get(id string) (*proxmox.ClusterResource, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, items := range s.items {
+		if r, ok := items[id]; ok {
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+// GetByVMID returns the VM or container resource by its ID.
+func (s *ResourceStore) GetByVMID(vmid uint64) (*proxmox.ClusterResource, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.vmids[vmid]
+
+	return r, ok
+}
+
+// List returns the resources of the type ("vm", "node", "storage").
+func (s *ResourceStore) List(name string) proxmox.ClusterResources {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.listLocked(name)
+}
+
+// ListByNode returns the resources of the type located on the node.
+func (s *ResourceStore) ListByNode(name, node string) proxmox.ClusterResources {
+	res := proxmox.ClusterResources{}
+
+	for _, r := range s.List(name) {
+		if r.Node == node {
+			res = append(res, r)
+		}
+	}
+
+	return res
+}
+
+func (s *ResourceStore) listLocked(name string) proxmox.ClusterResources {
+	items := s.items[name]
+	ids := make([]string, 0, len(items))
+
+	for id := range items {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	res := make(proxmox.ClusterResources, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, items[id])
+	}
+
+	return res
+}
+
+// list returns the resources if the type was synced and not invalidated since.
+func (s *ResourceStore) list(name string) (proxmox.ClusterResources, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.synced[name] || s.dirty[name] {
+		return nil, false
+	}
+
+	return s.listLocked(name), true
+}
+
+func (s *ResourceStore) hasSynced(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.synced[name]
+}
+
+func (s *ResourceStore) invalidate(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dirty[name] = true
+}
+
+// replace stores the new snapshot of the resource type and returns the changes.
+func (s *ResourceStore) replace(name string, resources proxmox.ClusterResources) []ResourceEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.items[name]
+	items := make(map[string]*proxmox.ClusterResource, len(resources))
+	events := []ResourceEvent{}
+
+	for _, r := range resources {
+		items[r.ID] = r
+
+		prev, ok := old[r.ID]
+
+		switch {
+		case !ok:
+			events = append(events, ResourceEvent{Type: ResourceAdded, Resource: r})
+		case r.VMID != 0 && prev.Node != r.Node:
+			events = append(events, ResourceEvent{Type: ResourceMigrated, Resource: r, Old: prev})
+		case !sameResource(prev, r):
+			events = append(events, ResourceEvent{Type: ResourceUpdated, Resource: r, Old: prev})
+		}
+	}
+
+	for id, r := range old {
+		if _, ok := items[id]; !ok {
+			events = append(events, ResourceEvent{Type: ResourceDeleted, Resource: r})
+		}
+	}
+
+	if name == "vm" {
+		clear(s.vmids)
+
+		for _, r := range resources {
+			s.vmids[r.VMID] = r
+		}
+	}
+
+	s.items[name] = items
+	s.synced[name] = true
+	s.dirty[name] = false
+
+	return events
+}
+
+// sameResource compares the resources ignoring the usage metrics.
+func sameResource(a, b *proxmox.ClusterResource) bool {
+	x, y := *a, *b
+
+	for _, r := range []*proxmox.ClusterResource{&x, &y} {
+		r.CPU, r.Mem, r.Disk, r.Uptime = 0, 0, 0, 0
+		r.NetIn, r.NetOut, r.DiskRead, r.DiskWrite = 0, 0, 0, 0
+	}
+
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestResourceInformer(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+	srv.AddVM("pve-1", 101, map[string]any{"name": "worker-2"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	informer := client.NewResourceInformer(time.Hour, "vm")
+	events := informer.Watch(ctx, 16)
+
+	go informer.Run(ctx)
+
+	next := func() goproxmox.ResourceEvent {
+		t.Helper()
+
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "no resource event")
+		}
+
+		return goproxmox.ResourceEvent{}
+	}
+
+	for range 3 {
+		assert.Equal(t, goproxmox.ResourceAdded, next().Type)
+	}
+
+	require.True(t, informer.HasSynced())
+
+	vm, ok := informer.Store().GetByVMID(100)
+	require.True(t, ok)
+	assert.Equal(t, "worker-1", vm.Name)
+
+	requests := srv.RequestCount(http.MethodGet, "/cluster/resources")
+	_, err := client.GetVMByID(ctx, 101)
+	require.NoError(t, err)
+	assert.Equal(t, requests, srv.RequestCount(http.MethodGet, "/cluster/resources"))
+
+	require.NoError(t, client.MigrateVMByID(ctx, 100, "pve-2", false))
+	_, err = client.GetVMByID(ctx, 100)
+	require.NoError(t, err)
+
+	ev := next()
+	assert.Equal(t, goproxmox.ResourceMigrated, ev.Type)
+	assert.Equal(t, "pve-1", ev.Old.Node)
+	assert.Equal(t, "pve-2", ev.Resource.Node)
+
+	_, err = client.StartVMByID(ctx, "pve-2", 100)
+	require.NoError(t, err)
+	_, err = client.GetVMByID(ctx, 100)
+	require.NoError(t, err)
+
+	ev = next()
+	assert.Equal(t, goproxmox.ResourceUpdated, ev.Type)
+	assert.Equal(t, "running", ev.Resource.Status)
+
+	require.NoError(t, client.DeleteVMByID(ctx, "pve-1", 101))
+	require.NoError(t, informer.Resync(ctx))
+
+	ev = next()
+	assert.Equal(t, goproxmox.ResourceDeleted, ev.Type)
+	assert.Equal(t, uint64(101), ev.Resource.VMID)
+}
+
+func TestResourceInformer_Watch(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	informer := client.NewResourceInformer(time.Hour, "vm")
+
+	// The events of the slow watcher are queued, they do not block the other watchers.
+	slowCtx, slowCancel := context.WithCancel(ctx)
+	slow := informer.Watch(slowCtx, 0)
+	events := informer.Watch(ctx, 0)
+
+	go informer.Run(ctx)
+
+	for range 2 {
+		select {
+		case ev := <-events:
+			assert.Equal(t, goproxmox.ResourceAdded, ev.Type)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "no resource event")
+		}
+	}
+
+	slowCancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for range slow {
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "watch channel is not closed")
+	}
+}
+
+func TestResourceInformer_DefaultInterval(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestCluster(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	informer := client.NewResourceInformer(0, "vm")
+
+	go informer.Run(ctx)
+
+	require.Eventually(t, informer.HasSynced, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+)
+
+const (
+	limitScopeRate    = "rate"
+	limitScopeNode    = "node"
+	limitScopeStorage = "storage"
+)
+
+type limitKey struct {
+	scope string
+	name  string
+}
+
+// limiter bounds the number of concurrent mutating tasks per node and per storage.
+// The zero limit means unlimited.
+type limiter struct {
+	nodeLimit    int
+	storageLimit int
+	failFast     bool
+
+	mu    sync.Mutex
+	slots map[limitKey]chan struct{}
+}
+
+func newLimiter(nodeLimit, storageLimit int, failFast bool) *limiter {
+	return &limiter{
+		nodeLimit:    nodeLimit,
+		storageLimit: storageLimit,
+		failFast:     failFast,
+		slots:        map[limitKey]chan struct{}{},
+	}
+}
+
+// acquire takes a task slot on each node and storage.
+// It blocks until the slots are available, or fails with LimitError in the fail fast mode.
+func (l *limiter) acquire(ctx context.Context, nodes []string, storages []string) (release func(), err error) {
+	keys := make([]limitKey, 0, len(nodes)+len(storages))
+
+	if l.nodeLimit > 0 {
+		for _, n := range nodes {
+			keys = append(keys, limitKey{limitScopeNode, n})
+		}
+	}
+
+	if l.storageLimit > 0 {
+		for _, s := range storages {
+			keys = append(keys, limitKey{limitScopeStorage, s})
+		}
+	}
+
+	// Acquire the slots in the same order to avoid deadlocks between operations on several nodes.
+	slices.SortFunc(keys, func(a, b limitKey) int {
+		return cmp.Or(cmp.Compare(a.scope, b.scope), cmp.Compare(a.name, b.name))
+	})
+	keys = slices.Compact(keys)
+
+	acquired := make([]chan struct{}, 0, len(keys))
+	release = func() {
+		for _, ch := range acquired {
+			<-ch
+		}
+	}
+
+	for _, k := range keys {
+		if k.name == "" {
+			continue
+		}
+
+		ch := l.slot(k)
+
+		if l.failFast {
+			select {
+			case ch <- struct{}{}:
+			default:
+				release()
+
+				return nil, &LimitError{Scope: k.scope, Name: k.name}
+			}
+		} else {
+			select {
+			case ch <- struct{}{}:
+			case <-ctx.Done():
+				release()
+
+				return nil, ctx.Err()
+			}
+		}
+
+		acquired = append(acquired, ch)
+	}
+
+	return release, nil
+}
+
+// storageSlots returns the limiter names of the storages on the node.
+// The node local storages are limited on each node, the shared storages across the cluster.
+func (c *APIClient) storageSlots(ctx context.Context, node string, storages []string) []string {
+	if c.limiter.storageLimit == 0 || node == "" {
+		return storages
+	}
+
+	shared := map[string]bool{}
+
+	// Without the storage list all the storages are limited per node.
+	if resources, err := c.getResources(ctx, "storage"); err == nil {
+		for _, r := range resources {
+			if r.Node == node && r.Shared == 1 {
+				shared[r.Storage] = true
+			}
+		}
+	}
+
+	res := make([]string, 0, len(storages))
+
+	for _, s := range storages {
+		if s == "" || shared[s] {
+			res = append(res, s)
+
+			continue
+		}
+
+		res = append(res, node+"/"+s)
+	}
+
+	return res
+}
+
+func (l *limiter) slot(k limitKey) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ch, ok := l.slots[k]
+	if !ok {
+		size := l.nodeLimit
+		if k.scope == limitScopeStorage {
+			size = l.storageLimit
+		}
+
+		ch = make(chan struct{}, size)
+		l.slots[k] = ch
+	}
+
+	return ch
+}
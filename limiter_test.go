@@ -0,0 +1,200 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestNodeConcurrency(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithNodeConcurrency(1))
+	srv.TaskDuration = 100 * time.Millisecond
+
+	var wg sync.WaitGroup
+
+	for i := range 3 {
+		wg.Go(func() {
+			err := client.CreateVM(context.Background(), "pve-1", map[string]interface{}{
+				"vmid":     9001 + i,
+				"name":     "template",
+				"template": 1,
+			})
+			assert.NoError(t, err)
+		})
+	}
+
+	wg.Wait()
+
+	var last time.Time
+
+	for _, task := range srv.Tasks() {
+		if task.Type != "qmcreate" {
+			continue
+		}
+
+		assert.False(t, task.StartTime.Before(last), "tasks on the node overlap")
+		last = task.EndTime
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithNodeConcurrency(1), goproxmox.WithFailFast())
+	srv.TaskDuration = 500 * time.Millisecond
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_, err := client.CloneVM(context.Background(), 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-1"})
+		assert.NoError(t, err)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, ok := srv.VM(100)
+
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	_, err := client.CloneVM(context.Background(), 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 101, Name: "worker-2"})
+	require.ErrorIs(t, err, goproxmox.ErrLimitExceeded)
+
+	var limitErr *goproxmox.LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, &goproxmox.LimitError{Scope: "node", Name: "pve-1"}, limitErr)
+
+	<-done
+
+	_, err = client.CloneVM(context.Background(), 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 101, Name: "worker-2"})
+	assert.NoError(t, err)
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestCluster(t, goproxmox.WithRateLimit(1, 1), goproxmox.WithFailFast(), goproxmox.WithCache(goproxmox.NoopCache{}))
+
+	_, err := client.GetVMTemplateByID(context.Background(), 9000)
+	require.NoError(t, err)
+
+	_, err = client.GetVMTemplateByID(context.Background(), 9000)
+	require.ErrorIs(t, err, goproxmox.ErrLimitExceeded)
+}
+
+func TestNodeConcurrency_CanceledWait(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithNodeConcurrency(1), goproxmox.WithFailFast())
+	srv.TaskDuration = 500 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := client.CreateVM(ctx, "pve-1", map[string]interface{}{"vmid": 100, "name": "worker-1"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	h, err := client.CreateVMAsync(context.Background(), "pve-1", map[string]interface{}{"vmid": 101, "name": "worker-2"})
+	require.NoError(t, err)
+
+	_, err = client.CloneVMAsync(context.Background(), 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 102, Name: "worker-3"})
+	require.ErrorIs(t, err, goproxmox.ErrLimitExceeded)
+
+	h.Close()
+
+	h, err = client.CloneVMAsync(context.Background(), 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 102, Name: "worker-3"})
+	require.NoError(t, err)
+	require.NoError(t, h.Wait(context.Background()))
+}
+
+func TestStorageConcurrency_LocalStorage(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithStorageConcurrency(1), goproxmox.WithFailFast())
+	srv.TaskDuration = 500 * time.Millisecond
+
+	ctx := context.Background()
+	handles := []*goproxmox.TaskHandle{}
+
+	create := func(node string, vmid int, disk string) error {
+		h, err := client.CreateVMAsync(ctx, node, map[string]interface{}{"vmid": vmid, "name": "worker", "scsi0": disk})
+		if err == nil {
+			handles = append(handles, h)
+		}
+
+		return err
+	}
+
+	// The local storages of different nodes are limited separately.
+	require.NoError(t, create("pve-1", 100, "local-lvm:1"))
+	require.NoError(t, create("pve-2", 101, "local-lvm:1"))
+
+	var limitErr *goproxmox.LimitError
+
+	require.ErrorAs(t, create("pve-1", 102, "local-lvm:1"), &limitErr)
+	assert.Equal(t, &goproxmox.LimitError{Scope: "storage", Name: "pve-1/local-lvm"}, limitErr)
+
+	// The shared storage is limited across the cluster.
+	require.NoError(t, create("pve-1", 103, "rbd:1"))
+
+	require.ErrorAs(t, create("pve-2", 104, "rbd:1"), &limitErr)
+	assert.Equal(t, &goproxmox.LimitError{Scope: "storage", Name: "rbd"}, limitErr)
+
+	require.NoError(t, goproxmox.WaitAll(ctx, handles...))
+}
+
+func TestStorageConcurrency_TargetStorage(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithStorageConcurrency(1), goproxmox.WithFailFast())
+	srv.TaskDuration = 500 * time.Millisecond
+
+	ctx := context.Background()
+
+	srv.AddContainer("pve-1", 200, map[string]any{"hostname": "ct-1", "rootfs": "rbd:vm-200-disk-0,size=4G"})
+
+	h, err := client.CreateVMAsync(ctx, "pve-2", map[string]interface{}{"vmid": 100, "name": "worker", "scsi0": "local-lvm:1"})
+	require.NoError(t, err)
+
+	var limitErr *goproxmox.LimitError
+
+	// The clone is migrated to the local storage of the target node.
+	_, err = client.CloneVMAsync(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", TargetNode: "pve-2", Storage: "local-lvm", NewID: 101, Name: "worker-1"})
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, &goproxmox.LimitError{Scope: "storage", Name: "pve-2/local-lvm"}, limitErr)
+
+	other, err := client.CreateVMAsync(ctx, "pve-1", map[string]interface{}{"vmid": 102, "name": "worker", "scsi0": "local-lvm:1"})
+	require.NoError(t, err)
+
+	// The mount point volume is allocated on the storage.
+	err = client.AttachCTMountPoint(ctx, 200, "mp0", goproxmox.CTMountPoint{Volume: "local-lvm:1", MountPoint: "/data"})
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, &goproxmox.LimitError{Scope: "storage", Name: "pve-1/local-lvm"}, limitErr)
+
+	require.NoError(t, goproxmox.WaitAll(ctx, h, other))
+}
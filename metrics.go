@@ -0,0 +1,182 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "proxmox"
+
+// metrics contains the collectors of the APIClient.
+// A nil *metrics is valid and records nothing.
+type metrics struct {
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	taskDuration    *prometheus.HistogramVec
+}
+
+// newMetrics registers the collectors of the client with the cluster label.
+// The clients with the same cluster label share the collectors registered by the first one.
+func newMetrics(reg prometheus.Registerer, cluster string, c *APIClient) (*metrics, error) {
+	constLabels := prometheus.Labels{"cluster": cluster}
+
+	m := &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   "api",
+			Name:        "requests_total",
+			Help:        "Number of Proxmox API requests by method, path template and status.",
+			ConstLabels: constLabels,
+		}, []string{"method", "path", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   "api",
+			Name:        "request_duration_seconds",
+			Help:        "Latency of Proxmox API requests by method, path template and status.",
+			Buckets:     prometheus.DefBuckets,
+			ConstLabels: constLabels,
+		}, []string{"method", "path", "status"}),
+		taskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   "task",
+			Name:        "wait_duration_seconds",
+			Help:        "Time spent waiting for Proxmox tasks by task type and outcome.",
+			Buckets:     []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+			ConstLabels: constLabels,
+		}, []string{"type", "outcome"}),
+	}
+
+	cacheStat := func(name, help string, value func(ResourceCacheStats) uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   "resource_cache",
+			Name:        name,
+			Help:        help,
+			ConstLabels: constLabels,
+		}, func() float64 {
+			return float64(value(c.ResourceCacheStats()))
+		})
+	}
+
+	var err error
+
+	m.requests, err = register(reg, m.requests)
+	if err != nil {
+		return nil, err
+	}
+
+	m.requestDuration, err = register(reg, m.requestDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	m.taskDuration, err = register(reg, m.taskDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stat := range []prometheus.Collector{
+		cacheStat("hits_total", "Number of cluster resource lookups served from the cache.",
+			func(s ResourceCacheStats) uint64 { return s.Hits }),
+		cacheStat("misses_total", "Number of cluster resource lookups not found in the cache.",
+			func(s ResourceCacheStats) uint64 { return s.Misses }),
+		cacheStat("coalesced_total", "Number of missed cluster resource lookups which waited for a request in flight.",
+			func(s ResourceCacheStats) uint64 { return s.Coalesced }),
+	} {
+		if _, err := register(reg, stat); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// register registers the collector, or returns the collector already registered with the same descriptors.
+func register[T prometheus.Collector](reg prometheus.Registerer, collector T) (T, error) {
+	err := reg.Register(collector)
+	if err == nil {
+		return collector, nil
+	}
+
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(T); ok {
+			return existing, nil
+		}
+
+		// The resource cache counters of another client with the same cluster label are kept.
+		return collector, nil
+	}
+
+	return collector, fmt.Errorf("unable to register metrics: %w", err)
+}
+
+func (m *metrics) observeRequest(method, path string, status string, d time.Duration) {
+	if m == nil {
+		return
+	}
+
+	path = pathTemplate(path)
+
+	m.requests.WithLabelValues(method, path, status).Inc()
+	m.requestDuration.WithLabelValues(method, path, status).Observe(d.Seconds())
+}
+
+func (m *metrics) observeTask(taskType, outcome string, d time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.taskDuration.WithLabelValues(taskType, outcome).Observe(d.Seconds())
+}
+
+// pathTemplate replaces the object names in the API path with placeholders,
+// e.g. /nodes/pve-1/qemu/100/config becomes /nodes/{node}/qemu/{vmid}/config.
+func pathTemplate(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i := 1; i < len(parts); i++ {
+		switch parts[i-1] {
+		case "nodes":
+			parts[i] = "{node}"
+		case "qemu", "lxc":
+			if _, err := strconv.Atoi(parts[i]); err == nil {
+				parts[i] = "{vmid}"
+			}
+		case "storage":
+			parts[i] = "{storage}"
+		case "tasks":
+			parts[i] = "{upid}"
+		case "pools":
+			parts[i] = "{poolid}"
+		case "snapshot":
+			parts[i] = "{snapname}"
+		case "content":
+			// Volume names contain slashes in some storages.
+			return "/" + strings.Join(append(parts[:i], "{volume}"), "/")
+		}
+	}
+
+	return "/" + strings.Join(parts, "/")
+}
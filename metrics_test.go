@@ -0,0 +1,157 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestWithMetrics(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	srv, client := newTestCluster(t, goproxmox.WithMetrics(reg))
+	srv.FailTask("qmclone", "clone failed")
+
+	ctx := context.Background()
+
+	_, err := client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-1"})
+	require.Error(t, err)
+
+	_, err = client.GetVMByID(ctx, 9000)
+	require.Error(t, err)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	metrics := map[string][]*dto.Metric{}
+	for _, f := range families {
+		metrics[f.GetName()] = f.GetMetric()
+	}
+
+	assert.Equal(t, 1.0, counterValue(metrics["proxmox_api_requests_total"],
+		map[string]string{"method": "POST", "path": "/nodes/{node}/qemu/{vmid}/clone", "status": "200"}))
+	assert.Equal(t, 1.0, counterValue(metrics["proxmox_api_requests_total"],
+		map[string]string{"method": "GET", "path": "/cluster/resources", "status": "200"}))
+
+	tasks := metrics["proxmox_task_wait_duration_seconds"]
+	require.Len(t, tasks, 1)
+	u, err := url.Parse(srv.URL())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cluster": u.Host, "type": "qmclone", "outcome": "failed"}, labels(tasks[0]))
+	assert.Equal(t, uint64(1), tasks[0].GetHistogram().GetSampleCount())
+
+	assert.Equal(t, 1.0, metrics["proxmox_resource_cache_misses_total"][0].GetCounter().GetValue())
+}
+
+func TestWithMetrics_Pools(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	srv, client := newTestCluster(t, goproxmox.WithMetrics(reg))
+	srv.AddPool("vmid-claim-500", time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+	srv.AddPool("vmid-claim-501", time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+
+	_, err := goproxmox.NewVMIDAllocator(client, goproxmox.WithVMIDRange(500, 501)).Reserve(context.Background(), "pve-1")
+	require.NoError(t, err)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	metrics := map[string][]*dto.Metric{}
+	for _, f := range families {
+		metrics[f.GetName()] = f.GetMetric()
+	}
+
+	// The claims share the path label.
+	assert.Equal(t, 2.0, counterValue(metrics["proxmox_api_requests_total"],
+		map[string]string{"method": "DELETE", "path": "/pools/{poolid}", "status": "200"}))
+}
+
+func labels(m *dto.Metric) map[string]string {
+	res := map[string]string{}
+	for _, l := range m.GetLabel() {
+		res[l.GetName()] = l.GetValue()
+	}
+
+	return res
+}
+
+func counterValue(metrics []*dto.Metric, match map[string]string) float64 {
+	for _, m := range metrics {
+		l := labels(m)
+
+		found := true
+		for k, v := range match {
+			if l[k] != v {
+				found = false
+			}
+		}
+
+		if found {
+			return m.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestWithMetrics_SharedRegistry(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	srv, client := newTestCluster(t, goproxmox.WithMetrics(reg), goproxmox.WithClusterName("cluster-1"))
+
+	other, err := goproxmox.NewAPIClientWithOptions(srv.URL(), goproxmox.WithMetrics(reg), goproxmox.WithClusterName("cluster-2"))
+	require.NoError(t, err)
+
+	same, err := goproxmox.NewAPIClientWithOptions(srv.URL(), goproxmox.WithMetrics(reg), goproxmox.WithClusterName("cluster-2"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	for _, c := range []*goproxmox.APIClient{client, other, same} {
+		_, err = c.GetVMTemplateByID(ctx, 9000)
+		require.NoError(t, err)
+	}
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	metrics := map[string][]*dto.Metric{}
+	for _, f := range families {
+		metrics[f.GetName()] = f.GetMetric()
+	}
+
+	requests := map[string]string{"method": "GET", "path": "/cluster/resources", "status": "200"}
+
+	requests["cluster"] = "cluster-1"
+	assert.Equal(t, 1.0, counterValue(metrics["proxmox_api_requests_total"], requests))
+
+	requests["cluster"] = "cluster-2"
+	assert.Equal(t, 2.0, counterValue(metrics["proxmox_api_requests_total"], requests))
+}
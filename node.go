@@ -27,7 +27,7 @@ import (
 func (c *APIClient) GetNodeList(ctx context.Context) ([]string, error) {
 	ns, err := c.Client.Nodes(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get node list: %v", err)
+		return nil, fmt.Errorf("failed to get node list: %w", err)
 	}
 
 	nodeList := []string{}
@@ -56,29 +56,13 @@ func (c *APIClient) GetNodeByName(ctx context.Context, nodeName string) (*proxmo
 	return nil, ErrNodeNotFound
 }
 
-// GetNodeListByFilter get cluster node resources by applying the provided filter functions.
-func (c *APIClient) GetNodeListByFilter(ctx context.Context, filter ...func(*proxmox.ClusterResource) (bool, error)) (nodes proxmox.ClusterResources, err error) {
+// GetNodeListByFilter get cluster node resources matching all the provided filter functions.
+// Use Or to match any of them.
+func (c *APIClient) GetNodeListByFilter(ctx context.Context, filter ...ResourceFilter) (proxmox.ClusterResources, error) {
 	resources, err := c.getResources(ctx, "node")
 	if err != nil {
 		return nil, err
 	}
 
-	if len(filter) == 0 {
-		return resources, nil
-	}
-
-	for _, node := range resources {
-		for _, f := range filter {
-			ok, err := f(node)
-			if err != nil {
-				return nil, err
-			}
-
-			if ok {
-				nodes = append(nodes, node)
-			}
-		}
-	}
-
-	return nodes, nil
+	return filterItems(resources, filter...)
 }
@@ -0,0 +1,258 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+// ClientOption configures the APIClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	proxmoxOptions []proxmox.Option
+	httpClient     *http.Client
+
+	resourceCache Cache
+	resourceTTL   map[string]time.Duration
+	vmidCache     Cache
+	vmidTTL       time.Duration
+
+	metricsRegisterer prometheus.Registerer
+	clusterName       string
+	tracerProvider    trace.TracerProvider
+
+	rateLimit          rate.Limit
+	rateBurst          int
+	nodeConcurrency    int
+	storageConcurrency int
+	failFast           bool
+
+	timeouts         TimeoutPolicy
+	taskPollInterval time.Duration
+	cancelTasks      bool
+	taskLogHandler   TaskLogFunc
+	taskLogTail      int
+	taskConflicts    TaskConflictPolicy
+
+	lockWait          time.Duration
+	lockRetryInterval time.Duration
+
+	templateReplicas TemplateReplicaFunc
+}
+
+func defaultClientOptions() clientOptions {
+	return clientOptions{
+		resourceTTL: map[string]time.Duration{
+			"node":    5 * time.Second,
+			"vm":      5 * time.Second,
+			"storage": time.Minute,
+		},
+		vmidTTL:          5 * time.Minute,
+		timeouts:         DefaultTimeoutPolicy(),
+		taskPollInterval: proxmox.DefaultWaitInterval,
+		taskLogTail:      10,
+		taskConflicts:    TaskConflictIgnore,
+
+		lockRetryInterval: time.Second,
+
+		templateReplicas: TemplateReplicaByName,
+	}
+}
+
+// WithProxmoxOptions passes options to the underlying go-proxmox client.
+func WithProxmoxOptions(options ...proxmox.Option) ClientOption {
+	return func(o *clientOptions) {
+		o.proxmoxOptions = append(o.proxmoxOptions, options...)
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for API requests.
+// Unlike proxmox.WithHTTPClient, it keeps the APIError handling of the client.
+func WithHTTPClient(client *http.Client) ClientOption {
+	return func(o *clientOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithCache sets the cache for cluster resources.
+// Use NoopCache to always fetch the resources from the API.
+func WithCache(cache Cache) ClientOption {
+	return func(o *clientOptions) {
+		o.resourceCache = cache
+	}
+}
+
+// WithResourceTTL sets the cache TTL for the cluster resource type ("node", "vm", "storage").
+// The zero TTL means the default expiration of the cache.
+func WithResourceTTL(resourceType string, ttl time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.resourceTTL[resourceType] = ttl
+	}
+}
+
+// WithVMIDCache sets the cache of recently allocated VM IDs used by GetNextID.
+func WithVMIDCache(cache Cache) ClientOption {
+	return func(o *clientOptions) {
+		o.vmidCache = cache
+	}
+}
+
+// WithVMIDTTL sets how long a VM ID returned by GetNextID is skipped by the following calls.
+func WithVMIDTTL(ttl time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.vmidTTL = ttl
+	}
+}
+
+// WithMetrics registers the API request, task and resource cache metrics of the client.
+// The metrics are not collected without this option.
+//
+// The metrics have the cluster label set with WithClusterName, so several clients can share the registerer.
+// The clients with the same label share the API request and task metrics.
+func WithMetrics(reg prometheus.Registerer) ClientOption {
+	return func(o *clientOptions) {
+		o.metricsRegisterer = reg
+	}
+}
+
+// WithClusterName sets the cluster label of the client metrics.
+// The default is the host of the API URL.
+func WithClusterName(name string) ClientOption {
+	return func(o *clientOptions) {
+		o.clusterName = name
+	}
+}
+
+// WithTracerProvider enables OpenTelemetry spans for the client operations, their steps and API requests.
+// The spans are not recorded without this option.
+func WithTracerProvider(tp trace.TracerProvider) ClientOption {
+	return func(o *clientOptions) {
+		o.tracerProvider = tp
+	}
+}
+
+// WithRateLimit limits the API requests of the client to rps requests per second with the burst size.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(o *clientOptions) {
+		o.rateLimit = rate.Limit(rps)
+		o.rateBurst = max(burst, 1)
+	}
+}
+
+// WithNodeConcurrency limits the number of concurrent mutating tasks (clone, create, migrate, start, delete, ...) per node.
+func WithNodeConcurrency(limit int) ClientOption {
+	return func(o *clientOptions) {
+		o.nodeConcurrency = limit
+	}
+}
+
+// WithStorageConcurrency limits the number of concurrent mutating tasks per storage.
+// The node local storages are limited on each node, the shared storages across the cluster.
+func WithStorageConcurrency(limit int) ClientOption {
+	return func(o *clientOptions) {
+		o.storageConcurrency = limit
+	}
+}
+
+// WithFailFast makes the calls fail with LimitError instead of waiting when a limit is reached.
+func WithFailFast() ClientOption {
+	return func(o *clientOptions) {
+		o.failFast = true
+	}
+}
+
+// WithOperationTimeout sets the maximum time to wait for the task of the operation.
+// The zero timeout means the wait is limited only by the context deadline.
+func WithOperationTimeout(op Operation, timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.timeouts[op] = timeout
+	}
+}
+
+// WithTimeoutPolicy replaces the task timeouts of all operations.
+func WithTimeoutPolicy(policy TimeoutPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.timeouts = TimeoutPolicy{}
+		maps.Copy(o.timeouts, policy)
+	}
+}
+
+// WithTaskPollInterval sets how often the status of a running task is checked.
+func WithTaskPollInterval(interval time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.taskPollInterval = interval
+	}
+}
+
+// WithTaskCancellation stops the Proxmox task when the context is done while the client waits for it.
+// The wait then returns TaskCanceledError.
+func WithTaskCancellation() ClientOption {
+	return func(o *clientOptions) {
+		o.cancelTasks = true
+	}
+}
+
+// WithTaskLogHandler passes the log lines of all tasks the client waits for to the function.
+// Use ContextWithTaskLog to follow the tasks of a single call.
+func WithTaskLogHandler(fn TaskLogFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.taskLogHandler = fn
+	}
+}
+
+// WithTaskLogTail sets the number of last log lines attached to TaskFailedError.
+func WithTaskLogTail(lines int) ClientOption {
+	return func(o *clientOptions) {
+		o.taskLogTail = lines
+	}
+}
+
+// WithTaskConflictPolicy sets how DeleteVMByID and UpdateVMByID handle the running tasks of the VM.
+// The default policy is TaskConflictIgnore.
+func WithTaskConflictPolicy(policy TaskConflictPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.taskConflicts = policy
+	}
+}
+
+// WithLockRetry retries the VM operations which fail because the VM is locked by another operation
+// ("can't lock file", "VM is locked"), until the lock clears or the timeout passes.
+// The delay between the attempts starts at the interval and doubles up to 10 seconds.
+func WithLockRetry(timeout, interval time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.lockWait = timeout
+		if interval > 0 {
+			o.lockRetryInterval = interval
+		}
+	}
+}
+
+// WithTemplateReplicas sets how CloneVM finds the replicas of a template on other nodes.
+// The default is TemplateReplicaByName.
+func WithTemplateReplicas(fn TemplateReplicaFunc) ClientOption {
+	return func(o *clientOptions) {
+		o.templateReplicas = fn
+	}
+}
@@ -19,6 +19,7 @@ package goproxmox
 import (
 	"context"
 	"fmt"
+	"net/url"
 
 	"github.com/luthermonson/go-proxmox"
 )
@@ -39,35 +40,15 @@ func (c *APIClient) GetClusterStorage(ctx context.Context, storage string) (*pro
 	return storages[0], nil
 }
 
-// GetClusterStoragesByFilter returns cluster storage resources by applying the provided filter functions.
-func (c *APIClient) GetClusterStoragesByFilter(ctx context.Context, filter ...func(*proxmox.ClusterResource) (bool, error)) (storages proxmox.ClusterResources, err error) {
+// GetClusterStoragesByFilter returns cluster storage resources matching all the provided filter functions.
+// Use Or to match any of them.
+func (c *APIClient) GetClusterStoragesByFilter(ctx context.Context, filter ...ResourceFilter) (proxmox.ClusterResources, error) {
 	resources, err := c.getResources(ctx, "storage")
 	if err != nil {
 		return nil, err
 	}
 
-	for _, storage := range resources {
-		if storage.Type != "storage" {
-			continue
-		}
-
-		if len(filter) == 0 {
-			storages = append(storages, storage)
-		}
-
-		for _, f := range filter {
-			ok, err := f(storage)
-			if err != nil {
-				return nil, err
-			}
-
-			if ok {
-				storages = append(storages, storage)
-			}
-		}
-	}
-
-	return storages, nil
+	return filterItems(resources, append([]ResourceFilter{isStorage}, filter...)...)
 }
 
 // GetNodesForStorage returns the node name list where the storage is available.
@@ -92,31 +73,15 @@ func (c *APIClient) GetNodesForStorage(ctx context.Context, storage string) ([]s
 	return nodes, nil
 }
 
-// GetStorageListByFilter get cluster storage list by applying the provided filter functions.
+// GetStorageListByFilter get cluster storage list matching all the provided filter functions.
+// Use Or to match any of them.
 func (c *APIClient) GetStorageListByFilter(ctx context.Context, filter ...func(*proxmox.ClusterStorage) (bool, error)) (proxmox.ClusterStorages, error) {
 	storages, err := c.Client.ClusterStorages(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(filter) == 0 {
-		return storages, nil
-	}
-
-	for _, storage := range storages {
-		for _, f := range filter {
-			ok, err := f(storage)
-			if err != nil {
-				return nil, err
-			}
-
-			if ok {
-				storages = append(storages, storage)
-			}
-		}
-	}
-
-	return storages, nil
+	return filterItems(storages, filter...)
 }
 
 // GetStorageStatus returns the storage status for a given storage on a given node.
@@ -126,5 +91,15 @@ func (c *APIClient) GetStorageStatus(ctx context.Context, node string, storage s
 
 // GetStorageContent returns the storage content for a given storage on a given node.
 func (c *APIClient) GetStorageContent(ctx context.Context, node string, storage string) (content []*proxmox.StorageContent, err error) {
-	return content, c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/storage/%s/content", node, storage), &content)
+	return c.getStorageContent(ctx, node, storage, nil)
+}
+
+// getStorageContent returns the storage content matching the query, e.g. the content type and the vmid.
+func (c *APIClient) getStorageContent(ctx context.Context, node string, storage string, query url.Values) (content []*proxmox.StorageContent, err error) {
+	path := fmt.Sprintf("/nodes/%s/storage/%s/content", node, storage)
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	return content, c.Client.Get(ctx, path, &content)
 }
@@ -0,0 +1,324 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+)
+
+// taskLogPageSize is the number of log lines requested at once.
+const taskLogPageSize = 500
+
+// TaskHandle is a Proxmox task started by an *Async method of the APIClient.
+//
+// Wait completes the operation: it waits for the task, runs the remaining
+// steps of the operation and releases the task concurrency slots of the client.
+// It must be called once the task is started, even if the result is not needed,
+// or Close must be called to give up on the operation.
+type TaskHandle struct {
+	client *APIClient
+	// task is polled by Wait, which updates it in place.
+	task *proxmox.Task
+	op   Operation
+	vmid int
+	desc string
+
+	// start starts the task again if it failed because the VM was locked.
+	start func(ctx context.Context) (*proxmox.Task, error)
+	lock  *lockRetry
+	// timeout replaces the operation timeout of the task wait, if set.
+	timeout time.Duration
+
+	// then runs the remaining steps of the operation after the task succeeded.
+	then    func(ctx context.Context) error
+	release func()
+	flush   bool
+
+	// taskMu guards the task ID, which changes when Wait starts the task again, and the cancel state.
+	taskMu   sync.Mutex
+	upid     proxmox.UPID
+	canceled bool
+
+	mu       sync.Mutex
+	taskDone bool
+	done     bool
+	err      error
+	released sync.Once
+}
+
+func (c *APIClient) newTaskHandle(op Operation, vmid int, desc string, release func()) *TaskHandle {
+	return &TaskHandle{
+		client:  c,
+		op:      op,
+		vmid:    vmid,
+		desc:    desc,
+		release: release,
+		flush:   true,
+	}
+}
+
+// startTask starts the task of the handle with fn.
+// The start is retried while the VM is locked, and so is the task if it fails because of the lock.
+func (h *TaskHandle) startTask(ctx context.Context, fn func(ctx context.Context) (*proxmox.Task, error)) error {
+	h.start = fn
+	h.lock = h.client.newLockRetry()
+
+	task, err := h.client.retryLocked(ctx, h.lock, fn)
+	if err != nil {
+		return err
+	}
+
+	h.setTask(task)
+
+	return nil
+}
+
+// UPID returns the ID of the task, or an empty string if the operation did not start a task.
+func (h *TaskHandle) UPID() string {
+	return string(h.currentUPID())
+}
+
+// currentUPID returns the ID of the task, the last one started if Wait started the task again.
+func (h *TaskHandle) currentUPID() proxmox.UPID {
+	h.taskMu.Lock()
+	defer h.taskMu.Unlock()
+
+	return h.upid
+}
+
+// setTask sets the task of the handle, nil if the request completed without a task.
+func (h *TaskHandle) setTask(task *proxmox.Task) {
+	h.taskMu.Lock()
+	defer h.taskMu.Unlock()
+
+	h.task = task
+	h.upid = ""
+
+	if task != nil {
+		h.upid = task.UPID
+	}
+}
+
+// restartTask replaces the task of the handle after Wait started it again.
+// The task is stopped if the handle was canceled meanwhile.
+func (h *TaskHandle) restartTask(ctx context.Context, task *proxmox.Task) error {
+	h.taskMu.Lock()
+	defer h.taskMu.Unlock()
+
+	h.task = task
+	h.upid = ""
+
+	if task != nil {
+		h.upid = task.UPID
+	}
+
+	if h.canceled && h.upid != "" {
+		return h.stopTask(ctx)
+	}
+
+	return nil
+}
+
+// VMID returns the ID of the VM the task operates on.
+func (h *TaskHandle) VMID() int {
+	return h.vmid
+}
+
+// Wait waits for the task and the remaining steps of the operation to complete.
+//
+// If the context is done while the task is still running, Wait returns the
+// context error and can be called again. The handle keeps its concurrency slots
+// until the next Wait completes or Close is called. Otherwise the result is final
+// and the following calls return it.
+func (h *TaskHandle) Wait(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.done {
+		return h.err
+	}
+
+	for !h.taskDone && h.task != nil {
+		timeout := h.client.timeouts[h.op]
+		if h.timeout > 0 {
+			timeout = h.timeout
+		}
+
+		err := h.client.waitTaskTimeout(ctx, h.task, timeout)
+		if err == nil {
+			break
+		}
+
+		if h.start != nil && h.lock.backoff(ctx, err) {
+			task, startErr := h.client.retryLocked(ctx, h.lock, h.start)
+			if startErr == nil {
+				startErr = h.restartTask(ctx, task)
+			}
+
+			if startErr == nil {
+				continue
+			}
+
+			err = startErr
+		}
+
+		var cancelErr *TaskCanceledError
+		if ctx.Err() != nil && !errors.As(err, &cancelErr) {
+			return fmt.Errorf("%s: %w", h.desc, err)
+		}
+
+		return h.complete(fmt.Errorf("%s: %w", h.desc, err))
+	}
+
+	h.taskDone = true
+
+	if h.then != nil {
+		return h.complete(h.then(ctx))
+	}
+
+	return h.complete(nil)
+}
+
+// Status returns the current status of the task, or nil if the operation did not start a task.
+func (h *TaskHandle) Status(ctx context.Context) (*proxmox.Task, error) {
+	upid := h.currentUPID()
+	if upid == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	task := proxmox.NewTask(upid, h.client.Client)
+	if err := task.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("unable to get status of task %s: %w", upid, err)
+	}
+
+	return task, nil
+}
+
+// Log returns the log lines of the task written so far.
+func (h *TaskHandle) Log(ctx context.Context) ([]string, error) {
+	upid := h.currentUPID()
+	if upid == "" {
+		return nil, nil
+	}
+
+	return h.client.taskLog(ctx, proxmox.NewTask(upid, h.client.Client), 0)
+}
+
+// Cancel stops the task. Wait returns the task failure afterwards.
+// A task started again by Wait while the VM was locked is stopped as well.
+func (h *TaskHandle) Cancel(ctx context.Context) error {
+	h.taskMu.Lock()
+	defer h.taskMu.Unlock()
+
+	if h.upid == "" {
+		return nil
+	}
+
+	h.canceled = true
+
+	return h.stopTask(ctx)
+}
+
+// stopTask stops the task of the handle, taskMu must be held.
+func (h *TaskHandle) stopTask(ctx context.Context) error {
+	if err := proxmox.NewTask(h.upid, h.client.Client).Stop(ctx); err != nil {
+		return fmt.Errorf("unable to stop task %s: %w", h.upid, err)
+	}
+
+	return nil
+}
+
+func (h *TaskHandle) complete(err error) error {
+	h.done = true
+	h.err = err
+	h.finish()
+
+	return err
+}
+
+// Close releases the task concurrency slots of the client held by the handle, e.g. when the
+// context of Wait is done and the caller gives up on the operation. The task keeps running.
+// Close is safe to call more than once and after Wait, which can still be called to get the result.
+func (h *TaskHandle) Close() {
+	h.finish()
+}
+
+// finish releases the concurrency slots and drops the cached VM resources and the indexed VM config.
+// The slots are released once, the cache is dropped on every call as the task may still change the VM.
+func (h *TaskHandle) finish() {
+	h.released.Do(func() {
+		if h.release != nil {
+			h.release()
+		}
+	})
+
+	if h.flush {
+		h.client.flushResources("vm")
+		h.client.vmIndex.forget(h.vmid)
+	}
+}
+
+// WaitAll waits for all the tasks and returns their joined errors.
+// Nil handles, e.g. of failed *Async calls, are skipped.
+func WaitAll(ctx context.Context, handles ...*TaskHandle) error {
+	errs := make([]error, len(handles))
+
+	var wg sync.WaitGroup
+
+	for i, h := range handles {
+		if h == nil {
+			continue
+		}
+
+		wg.Go(func() {
+			errs[i] = h.Wait(ctx)
+		})
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// taskLog returns the log lines of the task starting from the line.
+func (c *APIClient) taskLog(ctx context.Context, task *proxmox.Task, start int) ([]string, error) {
+	lines := []string{}
+
+	for {
+		page, err := task.Log(ctx, start+len(lines), taskLogPageSize)
+		if err != nil {
+			return lines, fmt.Errorf("unable to get log of task %s: %w", task.UPID, err)
+		}
+
+		keys := slices.Sorted(maps.Keys(page))
+		for _, k := range keys {
+			lines = append(lines, page[k])
+		}
+
+		if len(page) < taskLogPageSize {
+			return lines, nil
+		}
+	}
+}
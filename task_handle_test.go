@@ -0,0 +1,144 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestCloneVMAsync_WaitAll(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.TaskDuration = 200 * time.Millisecond
+
+	ctx := context.Background()
+	handles := []*goproxmox.TaskHandle{}
+
+	for i := range 3 {
+		h, err := client.CloneVMAsync(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100 + i, Name: "worker", Full: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 100+i, h.VMID())
+		assert.NotEmpty(t, h.UPID())
+
+		handles = append(handles, h)
+	}
+
+	// The handle of a failed call is nil, WaitAll skips it.
+	h, err := client.CloneVMAsync(ctx, 9999, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 200, Name: "worker"})
+	require.Error(t, err)
+	require.Nil(t, h)
+
+	handles = append(handles, h)
+
+	status, err := handles[0].Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "running", status.Status)
+
+	require.NoError(t, goproxmox.WaitAll(ctx, handles...))
+
+	for i := range 3 {
+		_, ok := srv.VM(100 + i)
+		assert.True(t, ok)
+	}
+
+	status, err = handles[0].Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, status.IsSuccessful)
+
+	log, err := handles[0].Log(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "TASK OK", log[len(log)-1])
+}
+
+func TestTaskHandle_Cancel(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.TaskDuration = time.Second
+
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "scsi0": "rbd:vm-100-disk-0,size=1G"})
+
+	h, err := client.MigrateVMByIDAsync(ctx, 100, "pve-2", false)
+	require.NoError(t, err)
+	require.NoError(t, h.Cancel(ctx))
+
+	err = h.Wait(ctx)
+	require.ErrorContains(t, err, "interrupted by signal")
+	assert.Equal(t, err, h.Wait(ctx))
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "pve-1", vm.Node)
+}
+
+func TestTaskHandle_CancelLockRetry(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithLockRetry(5*time.Second, 10*time.Millisecond))
+	srv.TaskDuration = 200 * time.Millisecond
+
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	_, err := client.StartVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	srv.FailTask("qmshutdown", "can't lock file '/var/lock/qemu-server/lock-100.conf' - got timeout")
+
+	h, err := client.ShutdownVMByIDAsync(ctx, "pve-1", 100, goproxmox.ShutdownOptions{Timeout: time.Second})
+	require.NoError(t, err)
+
+	upid := h.UPID()
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- h.Wait(ctx)
+	}()
+
+	// The status is read while Wait starts the shutdown again after the lock failure.
+	require.Eventually(t, func() bool {
+		_, err := h.Status(ctx)
+		require.NoError(t, err)
+
+		return h.UPID() != upid
+	}, 5*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, h.Cancel(ctx))
+
+	err = <-errCh
+	require.ErrorContains(t, err, "interrupted by signal")
+
+	tasks := srv.Tasks()
+	assert.Equal(t, h.UPID(), tasks[len(tasks)-1].UPID)
+	assert.Equal(t, "stopped", tasks[len(tasks)-1].Status)
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "running", vm.Status)
+}
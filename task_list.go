@@ -0,0 +1,214 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+)
+
+// TaskState selects the tasks by their state.
+type TaskState string
+
+const (
+	// TaskStateAny selects both running and finished tasks.
+	TaskStateAny TaskState = ""
+	// TaskStateRunning selects the running tasks.
+	TaskStateRunning TaskState = "running"
+	// TaskStateFinished selects the finished tasks.
+	TaskStateFinished TaskState = "finished"
+)
+
+// TaskFilter selects the tasks returned by ListTasks. The zero fields do not filter.
+type TaskFilter struct {
+	// Node lists the tasks of the node. Without a node the recent tasks of the cluster are listed.
+	Node string
+	// VMID is the ID of the VM the tasks belong to.
+	VMID int
+	// Type is the task type, e.g. qmclone.
+	Type string
+	// State selects the running or the finished tasks.
+	State TaskState
+	// Since selects the tasks started at or after the time.
+	Since time.Time
+	// Until selects the tasks started at or before the time.
+	Until time.Time
+	// Limit is the maximum number of tasks, the API returns 50 tasks of a node by default.
+	Limit int
+}
+
+// TaskInfo is a task listed by ListTasks.
+type TaskInfo struct {
+	UPID      string `json:"upid"`
+	Node      string `json:"node"`
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	User      string `json:"user"`
+	Status    string `json:"status,omitempty"` // exit status of a finished task
+	StartTime int64  `json:"starttime"`
+	EndTime   int64  `json:"endtime,omitempty"`
+}
+
+// IsRunning reports whether the task is still running.
+func (t *TaskInfo) IsRunning() bool {
+	return t.EndTime == 0 || t.Status == "" || strings.EqualFold(t.Status, "running")
+}
+
+// IsFailed reports whether the task finished with an error.
+func (t *TaskInfo) IsFailed() bool {
+	return !t.IsRunning() && t.Status != "OK"
+}
+
+// ListTasks returns the tasks matching the filter, newest first.
+func (c *APIClient) ListTasks(ctx context.Context, filter TaskFilter) ([]*TaskInfo, error) {
+	tasks := []*TaskInfo{}
+
+	if filter.Node == "" {
+		if err := c.Client.Get(ctx, "/cluster/tasks", &tasks); err != nil {
+			return nil, fmt.Errorf("unable to list cluster tasks: %w", err)
+		}
+	} else {
+		params := map[string]any{}
+
+		switch filter.State {
+		case TaskStateRunning:
+			params["source"] = "active"
+		case TaskStateFinished:
+			params["source"] = "archive"
+		default:
+			params["source"] = "all"
+		}
+
+		if filter.VMID != 0 {
+			params["vmid"] = filter.VMID
+		}
+
+		if filter.Type != "" {
+			params["typefilter"] = filter.Type
+		}
+
+		if !filter.Since.IsZero() {
+			params["since"] = filter.Since.Unix()
+		}
+
+		if !filter.Until.IsZero() {
+			params["until"] = filter.Until.Unix()
+		}
+
+		if filter.Limit > 0 {
+			params["limit"] = filter.Limit
+		}
+
+		if err := c.Client.GetWithParams(ctx, fmt.Sprintf("/nodes/%s/tasks", filter.Node), params, &tasks); err != nil {
+			return nil, fmt.Errorf("unable to list tasks of node %s: %w", filter.Node, err)
+		}
+	}
+
+	// The cluster task list is not filtered by the API.
+	res := make([]*TaskInfo, 0, len(tasks))
+
+	for _, t := range tasks {
+		if filter.match(t) {
+			res = append(res, t)
+		}
+
+		if filter.Limit > 0 && len(res) == filter.Limit {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// GetActiveTasksForVM returns the running tasks of the VM in the cluster.
+func (c *APIClient) GetActiveTasksForVM(ctx context.Context, vmID int) ([]*TaskInfo, error) {
+	return c.ListTasks(ctx, TaskFilter{VMID: vmID, State: TaskStateRunning})
+}
+
+func (f *TaskFilter) match(t *TaskInfo) bool {
+	switch {
+	case f.Node != "" && t.Node != f.Node,
+		f.VMID != 0 && t.ID != strconv.Itoa(f.VMID),
+		f.Type != "" && t.Type != f.Type,
+		f.State == TaskStateRunning && !t.IsRunning(),
+		f.State == TaskStateFinished && t.IsRunning(),
+		!f.Since.IsZero() && t.StartTime < f.Since.Unix(),
+		!f.Until.IsZero() && t.StartTime > f.Until.Unix():
+		return false
+	}
+
+	return true
+}
+
+// TaskConflictPolicy is how DeleteVMByID and UpdateVMByID handle the running tasks of the VM.
+type TaskConflictPolicy string
+
+const (
+	// TaskConflictIgnore starts the operation regardless of the running tasks.
+	// Proxmox may still fail it because the VM is locked.
+	TaskConflictIgnore TaskConflictPolicy = "ignore"
+	// TaskConflictWait waits for the running tasks to complete before the operation starts.
+	// The wait is bounded by the timeout of the operation.
+	TaskConflictWait TaskConflictPolicy = "wait"
+	// TaskConflictReject fails the operation with TaskConflictError.
+	TaskConflictReject TaskConflictPolicy = "reject"
+)
+
+// checkTaskConflicts applies the task conflict policy to the running tasks of the VM before the operation.
+func (c *APIClient) checkTaskConflicts(ctx context.Context, node string, vmID int, op Operation) error {
+	if c.taskConflicts != TaskConflictWait && c.taskConflicts != TaskConflictReject {
+		return nil
+	}
+
+	if timeout := c.timeouts[op]; timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for {
+		tasks, err := c.ListTasks(ctx, TaskFilter{Node: node, VMID: vmID, State: TaskStateRunning})
+		if err != nil {
+			return err
+		}
+
+		if len(tasks) == 0 {
+			return nil
+		}
+
+		if c.taskConflicts == TaskConflictReject {
+			res := &TaskConflictError{VMID: vmID}
+			for _, t := range tasks {
+				res.Tasks = append(res.Tasks, t.UPID)
+			}
+
+			return res
+		}
+
+		for _, t := range tasks {
+			if err := c.pollTask(ctx, proxmox.NewTask(proxmox.UPID(t.UPID), c.Client), nil); err != nil {
+				return fmt.Errorf("unable to wait for the running tasks of vm %d: %w", vmID, err)
+			}
+		}
+	}
+}
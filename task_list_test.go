@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestListTasks(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.TaskDuration = time.Second
+
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+	srv.AddVM("pve-1", 101, map[string]any{"name": "worker-2"})
+
+	require.NoError(t, client.UpdateVMByID(ctx, "pve-1", 101, map[string]any{"description": "test"}))
+
+	h, err := client.StartVMByIDAsync(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	tasks, err := client.ListTasks(ctx, goproxmox.TaskFilter{Node: "pve-1"})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, h.UPID(), tasks[0].UPID)
+	assert.True(t, tasks[0].IsRunning())
+	assert.Equal(t, "qmconfig", tasks[1].Type)
+	assert.False(t, tasks[1].IsRunning())
+	assert.False(t, tasks[1].IsFailed())
+
+	tasks, err = client.ListTasks(ctx, goproxmox.TaskFilter{Node: "pve-1", State: goproxmox.TaskStateFinished})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "101", tasks[0].ID)
+
+	tasks, err = client.ListTasks(ctx, goproxmox.TaskFilter{Node: "pve-2"})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	tasks, err = client.ListTasks(ctx, goproxmox.TaskFilter{Type: "qmconfig", Until: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	tasks, err = client.GetActiveTasksForVM(ctx, 100)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "qmstart", tasks[0].Type)
+
+	require.NoError(t, h.Wait(ctx))
+
+	tasks, err = client.GetActiveTasksForVM(ctx, 100)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestTaskConflictPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("reject", func(t *testing.T) {
+		t.Parallel()
+
+		srv, client := newTestCluster(t, goproxmox.WithTaskConflictPolicy(goproxmox.TaskConflictReject))
+		srv.TaskDuration = 300 * time.Millisecond
+
+		srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+		h, err := client.StartVMByIDAsync(ctx, "pve-1", 100)
+		require.NoError(t, err)
+
+		err = client.DeleteVMByID(ctx, "pve-1", 100)
+		require.ErrorIs(t, err, goproxmox.ErrTaskConflict)
+
+		var conflict *goproxmox.TaskConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, []string{h.UPID()}, conflict.Tasks)
+
+		err = client.UpdateVMByID(ctx, "pve-1", 100, map[string]any{"description": "test"})
+		require.ErrorIs(t, err, goproxmox.ErrTaskConflict)
+
+		require.NoError(t, h.Wait(ctx))
+		require.NoError(t, client.UpdateVMByID(ctx, "pve-1", 100, map[string]any{"description": "test"}))
+	})
+
+	t.Run("wait", func(t *testing.T) {
+		t.Parallel()
+
+		srv, client := newTestCluster(t, goproxmox.WithTaskConflictPolicy(goproxmox.TaskConflictWait))
+		srv.TaskDuration = 300 * time.Millisecond
+
+		srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+		_, err := client.StartVMByIDAsync(ctx, "pve-1", 100)
+		require.NoError(t, err)
+
+		require.NoError(t, client.DeleteVMByID(ctx, "pve-1", 100))
+
+		_, ok := srv.VM(100)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/luthermonson/go-proxmox"
+)
+
+var (
+	taskProgressRegexp    = regexp.MustCompile(`\((\d+(?:\.\d+)?)(?:/100)?%\)`)
+	taskTransferredRegexp = regexp.MustCompile(`transferred:? (\d+(?:\.\d+)?) ?([KMGTP]?i?B) of (\d+(?:\.\d+)?) ?([KMGTP]?i?B)`)
+)
+
+// TaskLogLine is a line of a Proxmox task log.
+type TaskLogLine struct {
+	// UPID is the ID of the task.
+	UPID string
+	// Type is the task type, e.g. qmclone.
+	Type string
+	// Number is the line number, starting from 0.
+	Number int
+	// Text is the line content.
+	Text string
+	// Progress is the completion percentage reported by the line, if HasProgress is true.
+	Progress    float64
+	HasProgress bool
+}
+
+// TaskLogFunc receives the log lines of a task while the client waits for it.
+type TaskLogFunc func(line TaskLogLine)
+
+type taskLogKey struct{}
+
+// ContextWithTaskLog returns a context which makes the client pass the log lines
+// of the tasks it waits for to the function.
+func ContextWithTaskLog(ctx context.Context, fn TaskLogFunc) context.Context {
+	handlers, _ := ctx.Value(taskLogKey{}).([]TaskLogFunc)
+
+	return context.WithValue(ctx, taskLogKey{}, append(handlers[:len(handlers):len(handlers)], fn))
+}
+
+// ParseTaskProgress returns the completion percentage reported by the log line
+// of a clone, migrate or move disk task.
+func ParseTaskProgress(line string) (float64, bool) {
+	if m := taskProgressRegexp.FindStringSubmatch(line); m != nil {
+		if p, err := strconv.ParseFloat(m[1], 64); err == nil {
+			return p, true
+		}
+	}
+
+	// Migration of the VM state reports only the transferred size.
+	if m := taskTransferredRegexp.FindStringSubmatch(line); m != nil {
+		done, total := parseLogSize(m[1], m[2]), parseLogSize(m[3], m[4])
+		if total > 0 {
+			return min(done/total*100, 100), true
+		}
+	}
+
+	return 0, false
+}
+
+func parseLogSize(value, unit string) float64 {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+
+	exp := strings.Index("BKMGTP", unit[:1])
+	if exp < 0 {
+		return v
+	}
+
+	mul := 1000.0
+	if strings.Contains(unit, "i") {
+		mul = 1024
+	}
+
+	for range exp {
+		v *= mul
+	}
+
+	return v
+}
+
+// taskLogFollower pages through the log of a running task.
+type taskLogFollower struct {
+	client   *APIClient
+	task     *proxmox.Task
+	handlers []TaskLogFunc
+	next     int
+	tail     []string
+	tailSize int
+}
+
+func (c *APIClient) newTaskLogFollower(ctx context.Context, task *proxmox.Task) *taskLogFollower {
+	handlers, _ := ctx.Value(taskLogKey{}).([]TaskLogFunc)
+	if c.taskLogHandler != nil {
+		handlers = append([]TaskLogFunc{c.taskLogHandler}, handlers...)
+	}
+
+	return &taskLogFollower{
+		client:   c,
+		task:     task,
+		handlers: handlers,
+		tailSize: c.taskLogTail,
+	}
+}
+
+// following reports whether the log lines are passed to handlers while the task runs.
+func (f *taskLogFollower) following() bool {
+	return len(f.handlers) > 0
+}
+
+// poll reads the new log lines, passes them to the handlers and keeps the last lines.
+func (f *taskLogFollower) poll(ctx context.Context) error {
+	lines, err := f.client.taskLog(ctx, f.task, f.next)
+
+	for _, text := range lines {
+		line := TaskLogLine{
+			UPID:   string(f.task.UPID),
+			Type:   f.task.Type,
+			Number: f.next,
+			Text:   text,
+		}
+		line.Progress, line.HasProgress = ParseTaskProgress(text)
+
+		for _, h := range f.handlers {
+			h(line)
+		}
+
+		f.next++
+
+		if f.tailSize > 0 && !strings.HasPrefix(text, "TASK ERROR:") {
+			f.tail = append(f.tail, text)
+			if len(f.tail) > f.tailSize {
+				f.tail = f.tail[len(f.tail)-f.tailSize:]
+			}
+		}
+	}
+
+	return err
+}
+
+// failure returns the error of the failed task with the last log lines.
+func (f *taskLogFollower) failure(ctx context.Context) *TaskFailedError {
+	f.poll(ctx) //nolint:errcheck
+
+	return &TaskFailedError{
+		UPID:       string(f.task.UPID),
+		Type:       f.task.Type,
+		ExitStatus: f.task.ExitStatus,
+		Log:        f.tail,
+	}
+}
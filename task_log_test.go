@@ -0,0 +1,85 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestParseTaskProgress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line     string
+		progress float64
+		ok       bool
+	}{
+		{line: "drive-scsi0: transferred 512.0 MiB of 32.0 GiB (1.56%) in 3s", progress: 1.56, ok: true},
+		{line: "transferred 2.0 GiB of 2.0 GiB (100.00%)", progress: 100, ok: true},
+		{line: "    (10.00/100%)", progress: 10, ok: true},
+		{line: "migration active, transferred 1.0 GiB of 4.0 GiB VM-state, 110.5 MiB/s", progress: 25, ok: true},
+		{line: "starting migration of VM 100 to node 'pve-2'"},
+		{line: "TASK OK"},
+	}
+
+	for _, tt := range tests {
+		progress, ok := goproxmox.ParseTaskProgress(tt.line)
+		assert.Equal(t, tt.ok, ok, tt.line)
+		assert.InDelta(t, tt.progress, progress, 0.001, tt.line)
+	}
+}
+
+func TestCloneVM_TaskLog(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.TaskDuration = 100 * time.Millisecond
+
+	var (
+		mu       sync.Mutex
+		lines    []string
+		progress []float64
+	)
+
+	ctx := goproxmox.ContextWithTaskLog(context.Background(), func(line goproxmox.TaskLogLine) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		lines = append(lines, line.Text)
+		if line.HasProgress && line.Type == "qmclone" {
+			progress = append(progress, line.Progress)
+		}
+	})
+
+	_, err := client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-1", Full: 1})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, "create full clone of drive scsi0 (local-lvm:base-9000-disk-0)", lines[0])
+	assert.Equal(t, []float64{0, 50, 100}, progress)
+	assert.Contains(t, lines, "TASK OK")
+}
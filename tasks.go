@@ -0,0 +1,240 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// taskStopTimeout is the maximum time to stop a task after the context was done.
+const taskStopTimeout = 30 * time.Second
+
+// Operation is a kind of Proxmox task the APIClient waits for.
+type Operation string
+
+const (
+	// OperationStart starts a VM.
+	OperationStart Operation = "start"
+	// OperationStop stops a VM.
+	OperationStop Operation = "stop"
+	// OperationShutdown shuts down the guest OS of a VM.
+	OperationShutdown Operation = "shutdown"
+	// OperationReboot reboots the guest OS of a VM.
+	OperationReboot Operation = "reboot"
+	// OperationReset resets a VM, like the reset button of a physical machine.
+	OperationReset Operation = "reset"
+	// OperationSuspend suspends a VM to RAM or to disk.
+	OperationSuspend Operation = "suspend"
+	// OperationResume resumes a suspended VM.
+	OperationResume Operation = "resume"
+	// OperationDelete deletes a VM.
+	OperationDelete Operation = "delete"
+	// OperationCreate creates a VM.
+	OperationCreate Operation = "create"
+	// OperationTemplate converts a VM to a template.
+	OperationTemplate Operation = "template"
+	// OperationClone clones a VM template.
+	OperationClone Operation = "clone"
+	// OperationMigrate migrates a VM to another node.
+	OperationMigrate Operation = "migrate"
+	// OperationRemoteMigrate migrates a VM to another cluster.
+	OperationRemoteMigrate Operation = "remote-migrate"
+	// OperationConfig updates the VM configuration, including disk attach and detach.
+	OperationConfig Operation = "config"
+	// OperationSnapshot creates a snapshot of a VM.
+	OperationSnapshot Operation = "snapshot"
+	// OperationSnapshotDelete deletes a snapshot of a VM.
+	OperationSnapshotDelete Operation = "snapshot-delete"
+	// OperationRollback rolls a VM back to a snapshot.
+	OperationRollback Operation = "rollback"
+	// OperationBackup creates a backup archive of a VM.
+	OperationBackup Operation = "backup"
+	// OperationRestore restores a VM from a backup archive.
+	OperationRestore Operation = "restore"
+	// OperationDiskMove moves a VM disk to another storage.
+	OperationDiskMove Operation = "disk-move"
+	// OperationDiskResize resizes a VM disk.
+	OperationDiskResize Operation = "disk-resize"
+	// OperationDiskDelete deletes a disk volume from a storage.
+	OperationDiskDelete Operation = "disk-delete"
+)
+
+// TimeoutPolicy is the maximum time to wait for the task of each operation.
+// Operations missing from the policy are not limited, except by the context deadline.
+type TimeoutPolicy map[Operation]time.Duration
+
+// DefaultTimeoutPolicy returns the default task timeouts.
+// The backup, restore, disk move and remote migration tasks take time proportional to the disk size and are not limited.
+func DefaultTimeoutPolicy() TimeoutPolicy {
+	return TimeoutPolicy{
+		OperationStart:          time.Minute,
+		OperationStop:           time.Minute,
+		OperationShutdown:       5 * time.Minute,
+		OperationReboot:         5 * time.Minute,
+		OperationReset:          time.Minute,
+		OperationSuspend:        5 * time.Minute,
+		OperationResume:         time.Minute,
+		OperationDelete:         time.Minute,
+		OperationTemplate:       time.Minute,
+		OperationCreate:         5 * time.Minute,
+		OperationClone:          5 * time.Minute,
+		OperationMigrate:        5 * time.Minute,
+		OperationConfig:         5 * time.Minute,
+		OperationSnapshot:       5 * time.Minute,
+		OperationSnapshotDelete: 5 * time.Minute,
+		OperationRollback:       5 * time.Minute,
+		OperationDiskResize:     5 * time.Minute,
+		OperationDiskDelete:     30 * time.Second,
+	}
+}
+
+// waitTask waits for the task of the operation to complete and records the wait duration and the task outcome.
+// The wait is bounded by the operation timeout and the context deadline, whichever comes first.
+// A failed task is returned as TaskFailedError.
+func (c *APIClient) waitTask(ctx context.Context, task *proxmox.Task, op Operation) error {
+	return c.waitTaskTimeout(ctx, task, c.timeouts[op])
+}
+
+// waitTaskTimeout waits for the task like waitTask, with the timeout instead of the operation timeout.
+func (c *APIClient) waitTaskTimeout(ctx context.Context, task *proxmox.Task, timeout time.Duration) (err error) {
+	ctx, span := c.startSpan(ctx, "task.wait", AttrNode.String(task.Node), AttrUPID.String(string(task.UPID)), AttrTaskType.String(task.Type))
+	defer func() { endSpan(span, err) }()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	follower := c.newTaskLogFollower(ctx, task)
+
+	err = c.pollTask(ctx, task, follower)
+	if err != nil && ctx.Err() != nil && c.cancelTasks {
+		err = c.stopTask(ctx, task, follower, err)
+	}
+
+	outcome := "ok"
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		outcome = "timeout"
+	case errors.Is(err, context.Canceled):
+		outcome = "canceled"
+	case err != nil:
+		outcome = "error"
+	case task.IsFailed:
+		outcome = "failed"
+		span.SetStatus(codes.Error, task.ExitStatus)
+	}
+
+	span.SetAttributes(attribute.String("proxmox.task.outcome", outcome))
+	c.metrics.observeTask(task.Type, outcome, time.Since(start))
+
+	if err == nil && task.IsFailed {
+		return follower.failure(ctx)
+	}
+
+	return err
+}
+
+// pollTask refreshes the task status until it is completed or the context is done.
+// The log lines are passed to the follower handlers as they appear.
+func (c *APIClient) pollTask(ctx context.Context, task *proxmox.Task, follower *taskLogFollower) error {
+	ticker := time.NewTicker(c.taskPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := task.Ping(ctx); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fmt.Errorf("task %s: %w", task.UPID, ctxErr)
+			}
+
+			return err
+		}
+
+		if follower != nil && follower.following() {
+			follower.poll(ctx) //nolint:errcheck
+		}
+
+		if task.IsCompleted {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("task %s: %w", task.UPID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// stopTask stops the task after the context was done and reports how the task ended.
+func (c *APIClient) stopTask(ctx context.Context, task *proxmox.Task, follower *taskLogFollower, cause error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), taskStopTimeout)
+	defer cancel()
+
+	res := &TaskCanceledError{UPID: string(task.UPID), Err: cause}
+
+	// The task may have completed since the last poll, the stop request would not tell.
+	if err := task.Ping(ctx); err == nil && task.IsCompleted {
+		return finishedTask(ctx, res, task, follower)
+	}
+
+	if err := task.Stop(ctx); err != nil {
+		if pingErr := task.Ping(ctx); pingErr == nil && task.IsCompleted {
+			return finishedTask(ctx, res, task, follower)
+		}
+
+		res.Result = TaskStopFailed
+		res.StopErr = err
+
+		return res
+	}
+
+	if err := c.pollTask(ctx, task, nil); err != nil {
+		res.Result = TaskStopFailed
+		res.StopErr = err
+
+		return res
+	}
+
+	res.Result = TaskStopped
+	if task.IsSuccessful {
+		res.Result = TaskFinished
+	}
+
+	return res
+}
+
+// finishedTask reports a task which completed on its own before it could be stopped.
+func finishedTask(ctx context.Context, res *TaskCanceledError, task *proxmox.Task, follower *taskLogFollower) error {
+	res.Result = TaskFinished
+	if task.IsFailed {
+		res.TaskErr = follower.failure(ctx)
+	}
+
+	return res
+}
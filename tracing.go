@@ -0,0 +1,68 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/sergelogvinov/go-proxmox"
+
+// Span attribute keys.
+const (
+	AttrNode     = attribute.Key("proxmox.node")
+	AttrVMID     = attribute.Key("proxmox.vmid")
+	AttrStorage  = attribute.Key("proxmox.storage")
+	AttrUPID     = attribute.Key("proxmox.upid")
+	AttrTaskType = attribute.Key("proxmox.task.type")
+)
+
+// startSpan starts a span of the APIClient operation or its sub-step.
+func (c *APIClient) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return c.tracer.Start(ctx, "proxmox."+name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error of the operation and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// startHTTPSpan starts a client span of the API request.
+func startHTTPSpan(tracer trace.Tracer, req *http.Request) (*http.Request, trace.Span) {
+	path := pathTemplate(apiPath(req))
+
+	ctx, span := tracer.Start(req.Context(), "HTTP "+req.Method+" "+path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.path", path),
+			attribute.String("server.address", req.URL.Hostname()),
+		),
+	)
+
+	return req.WithContext(ctx), span
+}
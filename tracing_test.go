@@ -0,0 +1,74 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestWithTracerProvider(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	_, client := newTestCluster(t, goproxmox.WithTracerProvider(tp))
+
+	ctx, root := tp.Tracer("test").Start(context.Background(), "reconcile")
+
+	_, err := client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-1", DiskSize: "10G"})
+	require.NoError(t, err)
+
+	root.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		if _, ok := spans[s.Name()]; !ok {
+			spans[s.Name()] = s
+		}
+	}
+
+	for _, name := range []string{
+		"proxmox.CloneVM",
+		"proxmox.task.wait",
+		"proxmox.vm.resizeDisk",
+		"proxmox.vm.waitStatus",
+		"HTTP POST /nodes/{node}/qemu/{vmid}/clone",
+	} {
+		require.Contains(t, spans, name)
+		assert.Equal(t, root.SpanContext().TraceID(), spans[name].SpanContext().TraceID(), name)
+	}
+
+	clone := spans["proxmox.CloneVM"]
+	assert.Equal(t, root.SpanContext().SpanID(), clone.Parent().SpanID())
+	assert.Contains(t, clone.Attributes(), goproxmox.AttrVMID.Int(100))
+	assert.Contains(t, clone.Attributes(), goproxmox.AttrNode.String("pve-1"))
+
+	task := spans["proxmox.task.wait"]
+	assert.Equal(t, clone.SpanContext().SpanID(), task.Parent().SpanID())
+	assert.Contains(t, task.Attributes(), goproxmox.AttrTaskType.String("qmclone"))
+	assert.Contains(t, task.Attributes(), attribute.String("proxmox.task.outcome", "ok"))
+}
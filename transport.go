@@ -0,0 +1,165 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+const apiPathPrefix = "/api2/json"
+
+// transport converts Proxmox API error responses to APIError.
+//
+// The go-proxmox client drops the status code and most of the error details,
+// so error responses are intercepted before they reach it. Unauthorized
+// responses are passed through, the client uses them to renew the session.
+type transport struct {
+	base    http.RoundTripper
+	metrics *metrics
+	tracer  trace.Tracer
+
+	rate     *rate.Limiter
+	failFast bool
+}
+
+// httpTransport returns the transport for the go-proxmox client.
+//
+// go-proxmox takes the TLS settings for websocket connections from the client
+// transport and expects an *http.Transport there. The returned transport keeps
+// the TLS settings of the base transport, and hands the API requests over to t
+// as the handler of the http and https schemes.
+func (t *transport) httpTransport() *http.Transport {
+	ht := &http.Transport{}
+	if base, ok := t.base.(*http.Transport); ok {
+		ht = base.Clone()
+	}
+
+	// The transport never dials the API, HTTP/2 would register its own https handler.
+	ht.Protocols = &http.Protocols{}
+	ht.Protocols.SetHTTP1(true)
+
+	ht.RegisterProtocol("http", t)
+	ht.RegisterProtocol("https", t)
+
+	return ht
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.waitRate(req); err != nil {
+		if req.Body != nil {
+			req.Body.Close() //nolint:errcheck
+		}
+
+		return nil, err
+	}
+
+	start := time.Now()
+
+	req, span := startHTTPSpan(t.tracer, req)
+	defer span.End()
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.metrics.observeRequest(req.Method, apiPath(req), "error", time.Since(start))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	t.metrics.observeRequest(req.Method, apiPath(req), strconv.Itoa(res.StatusCode), time.Since(start))
+	span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+
+	if res.StatusCode < http.StatusBadRequest || res.StatusCode == http.StatusUnauthorized {
+		return res, nil
+	}
+
+	defer res.Body.Close() //nolint:errcheck
+
+	apiErr := newAPIError(req, res)
+	span.SetStatus(codes.Error, apiErr.Message)
+
+	return nil, apiErr
+}
+
+// waitRate blocks until the request fits the global rate limit.
+func (t *transport) waitRate(req *http.Request) error {
+	if t.rate == nil {
+		return nil
+	}
+
+	if t.failFast {
+		if !t.rate.Allow() {
+			return &LimitError{Scope: limitScopeRate}
+		}
+
+		return nil
+	}
+
+	return t.rate.Wait(req.Context())
+}
+
+func newAPIError(req *http.Request, res *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Method:     req.Method,
+		Path:       apiPath(req),
+	}
+
+	// pveproxy sends the error message as the HTTP reason phrase.
+	apiErr.Message = strings.TrimSpace(strings.TrimPrefix(res.Status, strconv.Itoa(res.StatusCode)))
+
+	body := struct {
+		Message string            `json:"message"`
+		Errors  map[string]string `json:"errors"`
+	}{}
+
+	if data, err := io.ReadAll(io.LimitReader(res.Body, 1<<20)); err == nil {
+		json.Unmarshal(data, &body) //nolint:errcheck
+	}
+
+	if body.Message != "" && (apiErr.Message == "" || apiErr.Message == http.StatusText(res.StatusCode)) {
+		apiErr.Message = strings.TrimSpace(body.Message)
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(res.StatusCode)
+	}
+
+	apiErr.Errors = body.Errors
+
+	return apiErr
+}
+
+func apiPath(req *http.Request) string {
+	path := req.URL.Path
+	if i := strings.Index(path, apiPathPrefix); i >= 0 {
+		path = path[i+len(apiPathPrefix):]
+	}
+
+	return path
+}
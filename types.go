@@ -27,13 +27,17 @@ import (
 
 // VMCloneRequest represents a request to clone a virtual machine.
 type VMCloneRequest struct {
-	Node        string `json:"node"`
+	// Node is the node of the template, it is looked up if empty.
+	Node string `json:"node"`
+	// TargetNode is the node of the new VM, the default is the node of the template.
+	TargetNode  string `json:"targetNode,omitempty"`
 	NewID       int    `json:"newid"`
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
 	Full        uint8  `json:"full,omitempty"`
 	Pool        string `json:"pool,omitempty"`
-	Storage     string `json:"storage,omitempty"`
+	// Storage is the storage of the full clone disks on the target node.
+	Storage string `json:"storage,omitempty"`
 
 	CPU          int                   `json:"cpu,omitempty"`
 	CPUAffinity  string                `json:"cpuAffinity,omitempty"`
@@ -181,6 +185,82 @@ func (r *VMNUMA) ToString() (string, error) {
 	return marshal(r)
 }
 
+// CTCloneRequest represents a request to clone an LXC container.
+type CTCloneRequest struct {
+	Node        string `json:"node"`
+	NewID       int    `json:"newid"`
+	Hostname    string `json:"hostname"`
+	Description string `json:"description,omitempty"`
+	Full        uint8  `json:"full,omitempty"`
+	Pool        string `json:"pool,omitempty"`
+	Storage     string `json:"storage,omitempty"`
+
+	CPU      int    `json:"cpu,omitempty"`
+	Memory   uint32 `json:"memory,omitempty"`
+	DiskSize string `json:"diskSize,omitempty"`
+	Tags     string `json:"tags,omitempty"`
+}
+
+// CTNetworkDevice represents a network device configuration for an LXC container.
+type CTNetworkDevice struct {
+	Name     string             `json:"name"`
+	Bridge   string             `json:"bridge,omitempty"`
+	Firewall *proxmox.IntOrBool `json:"firewall,omitempty"`
+	Gateway  string             `json:"gw,omitempty"`
+	Gateway6 string             `json:"gw6,omitempty"`
+	HWAddr   string             `json:"hwaddr,omitempty"`
+	IP       string             `json:"ip,omitempty"`
+	IP6      string             `json:"ip6,omitempty"`
+	LinkDown *proxmox.IntOrBool `json:"link_down,omitempty"`
+	MTU      *int               `json:"mtu,omitempty"`
+	Rate     string             `json:"rate,omitempty"`
+	Tag      *int               `json:"tag,omitempty"`
+	Trunks   []int              `json:"trunks,omitempty"`
+	Type     string             `json:"type,omitempty"`
+}
+
+func (r *CTNetworkDevice) UnmarshalString(s string) error {
+	return unmarshal(s, r)
+}
+
+// ToString converts the CTNetworkDevice struct to its string representation.
+func (r *CTNetworkDevice) ToString() (string, error) {
+	return marshal(r)
+}
+
+// CTMountPoint represents a root filesystem or a mount point configuration for an LXC container.
+// The volume is the first element of the property string and has no key, like "local-lvm:vm-100-disk-1,mp=/data".
+type CTMountPoint struct {
+	Volume       string             `json:"volume"`
+	MountPoint   string             `json:"mp,omitempty"`
+	ACL          *proxmox.IntOrBool `json:"acl,omitempty"`
+	Backup       *proxmox.IntOrBool `json:"backup,omitempty"`
+	MountOptions []string           `json:"mountoptions,omitempty"`
+	Quota        *proxmox.IntOrBool `json:"quota,omitempty"`
+	ReadOnly     *proxmox.IntOrBool `json:"ro,omitempty"`
+	Replicate    *proxmox.IntOrBool `json:"replicate,omitempty"`
+	Shared       *proxmox.IntOrBool `json:"shared,omitempty"`
+	Size         string             `json:"size,omitempty"`
+}
+
+func (r *CTMountPoint) UnmarshalString(s string) error {
+	if volume, _, _ := strings.Cut(s, ","); volume != "" && !strings.Contains(volume, "=") {
+		s = "volume=" + s
+	}
+
+	return unmarshal(s, r)
+}
+
+// ToString converts the CTMountPoint struct to its string representation.
+func (r *CTMountPoint) ToString() (string, error) {
+	s, err := marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimPrefix(s, "volume="), nil
+}
+
 // HAGroup represents a High Availability group configuration.
 type HAGroup struct {
 	Group      string             `json:"group"`
@@ -299,7 +379,7 @@ func unmarshal(s string, v interface{}) error {
 
 	pairs := strings.Split(s, ",")
 	for _, p := range pairs {
-		v := strings.Split(strings.TrimSpace(p), "=")
+		v := strings.SplitN(strings.TrimSpace(p), "=", 2)
 
 		if len(v) == 2 {
 			for i := range psCount {
@@ -62,6 +62,20 @@ func TestVMCloudInitIPConfig_UnmarshalString(t *testing.T) {
 	}
 }
 
+func TestVMSMBIOS_UnmarshalString(t *testing.T) {
+	t.Parallel()
+
+	res := goproxmox.VMSMBIOS{}
+
+	err := res.UnmarshalString("base64=1,serial=aD13b3JrZXItMTtpPTEwMA==,sku=,uuid=6f1f0c2e-3a4b-4c5d-8e9f-0a1b2c3d4e5f")
+	assert.NoError(t, err)
+	assert.Equal(t, goproxmox.VMSMBIOS{
+		Base64: goproxmox.NewIntOrBool(true),
+		Serial: "aD13b3JrZXItMTtpPTEwMA==",
+		UUID:   "6f1f0c2e-3a4b-4c5d-8e9f-0a1b2c3d4e5f",
+	}, res)
+}
+
 func TestVMNetworkDevice_UnmarshalString(t *testing.T) {
 	t.Parallel()
 
@@ -265,3 +279,134 @@ func TestVMNUMA_ToString(t *testing.T) {
 		})
 	}
 }
+
+func TestCTNetworkDevice_UnmarshalString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		iface    goproxmox.CTNetworkDevice
+	}{
+		{
+			name:     "empty",
+			template: "",
+			iface:    goproxmox.CTNetworkDevice{},
+		},
+		{
+			name:     "static",
+			template: "name=eth0,bridge=vmbr0,firewall=1,gw=10.0.0.1,hwaddr=BC:24:11:00:00:02,ip=10.0.0.2/24,ip6=auto,mtu=1500,tag=10,type=veth",
+			iface: goproxmox.CTNetworkDevice{
+				Name:     "eth0",
+				Bridge:   "vmbr0",
+				Firewall: goproxmox.NewIntOrBool(true),
+				Gateway:  "10.0.0.1",
+				HWAddr:   "BC:24:11:00:00:02",
+				IP:       "10.0.0.2/24",
+				IP6:      "auto",
+				MTU:      ptr.To(1500),
+				Tag:      ptr.To(10),
+				Type:     "veth",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res := goproxmox.CTNetworkDevice{}
+
+			err := res.UnmarshalString(tt.template)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.iface, res)
+		})
+	}
+}
+
+func TestCTNetworkDevice_ToString(t *testing.T) {
+	t.Parallel()
+
+	iface := goproxmox.CTNetworkDevice{
+		Name:   "eth0",
+		Bridge: "vmbr0",
+		IP:     "dhcp",
+		Trunks: []int{1, 2},
+	}
+
+	res, err := iface.ToString()
+	assert.NoError(t, err)
+	assert.Equal(t, "name=eth0,bridge=vmbr0,ip=dhcp,trunks=1;2", res)
+}
+
+func TestCTMountPoint_UnmarshalString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		mount    goproxmox.CTMountPoint
+	}{
+		{
+			name:     "empty",
+			template: "",
+			mount:    goproxmox.CTMountPoint{},
+		},
+		{
+			name:     "rootfs",
+			template: "local-lvm:vm-100-disk-0,size=8G",
+			mount: goproxmox.CTMountPoint{
+				Volume: "local-lvm:vm-100-disk-0",
+				Size:   "8G",
+			},
+		},
+		{
+			name:     "mount-point",
+			template: "rbd:vm-100-disk-1,mp=/data,backup=1,mountoptions=noatime;nodev,ro=0,size=32G",
+			mount: goproxmox.CTMountPoint{
+				Volume:       "rbd:vm-100-disk-1",
+				MountPoint:   "/data",
+				Backup:       goproxmox.NewIntOrBool(true),
+				MountOptions: []string{"noatime", "nodev"},
+				ReadOnly:     goproxmox.NewIntOrBool(false),
+				Size:         "32G",
+			},
+		},
+		{
+			name:     "volume-key",
+			template: "volume=/mnt/data,mp=/data,shared=1",
+			mount: goproxmox.CTMountPoint{
+				Volume:     "/mnt/data",
+				MountPoint: "/data",
+				Shared:     goproxmox.NewIntOrBool(true),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res := goproxmox.CTMountPoint{}
+
+			err := res.UnmarshalString(tt.template)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.mount, res)
+		})
+	}
+}
+
+func TestCTMountPoint_ToString(t *testing.T) {
+	t.Parallel()
+
+	mount := goproxmox.CTMountPoint{
+		Volume:       "local-lvm:8",
+		MountPoint:   "/data",
+		MountOptions: []string{"noatime"},
+		Replicate:    goproxmox.NewIntOrBool(false),
+	}
+
+	res, err := mount.ToString()
+	assert.NoError(t, err)
+	assert.Equal(t, "local-lvm:8,mp=/data,mountoptions=noatime,replicate=0", res)
+}
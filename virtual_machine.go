@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/luthermonson/go-proxmox"
 )
@@ -41,74 +40,27 @@ func (c *APIClient) GetVMByID(ctx context.Context, vmID uint64) (*proxmox.Cluste
 	return nil, ErrVirtualMachineNotFound
 }
 
-// GetVMByFilter returns a VM cluster resource by applying the provided filter functions.
-func (c *APIClient) GetVMByFilter(ctx context.Context, filter ...func(*proxmox.ClusterResource) (bool, error)) (*proxmox.ClusterResource, error) {
-	vmr, err := c.getResources(ctx, "vm")
+// GetVMByFilter returns the first VM cluster resource matching all the provided filter functions.
+func (c *APIClient) GetVMByFilter(ctx context.Context, filter ...ResourceFilter) (*proxmox.ClusterResource, error) {
+	vms, err := c.GetVMsByFilter(ctx, filter...)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, vm := range vmr {
-		if vm.Template == 1 {
-			continue
-		}
-
-		if vm.Type != "qemu" { //nolint:goconst
-			continue
-		}
-
-		if len(filter) == 0 {
-			return vm, nil
-		}
-
-		for _, f := range filter {
-			ok, err := f(vm)
-			if err != nil {
-				return nil, err
-			}
-
-			if ok {
-				return vm, nil
-			}
-		}
-	}
-
-	return nil, ErrVirtualMachineNotFound
+	return vms[0], nil
 }
 
-// GetVMsByFilter returns a VM cluster resource by applying the provided filter functions.
-// nolint: dupl
-func (c *APIClient) GetVMsByFilter(ctx context.Context, filter ...func(*proxmox.ClusterResource) (bool, error)) (proxmox.ClusterResources, error) {
+// GetVMsByFilter returns the VM cluster resources matching all the provided filter functions.
+// Use Or to match any of them.
+func (c *APIClient) GetVMsByFilter(ctx context.Context, filter ...ResourceFilter) (proxmox.ClusterResources, error) {
 	vmr, err := c.getResources(ctx, "vm")
 	if err != nil {
 		return nil, err
 	}
 
-	vms := proxmox.ClusterResources{}
-
-	for _, vm := range vmr {
-		if vm.Template == 1 {
-			continue
-		}
-
-		if vm.Type != "qemu" {
-			continue
-		}
-
-		if len(filter) == 0 {
-			vms = append(vms, vm)
-		}
-
-		for _, f := range filter {
-			ok, err := f(vm)
-			if err != nil {
-				return nil, err
-			}
-
-			if ok {
-				vms = append(vms, vm)
-			}
-		}
+	vms, err := filterItems(vmr, append([]ResourceFilter{isVM}, filter...)...)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(vms) > 0 {
@@ -134,39 +86,17 @@ func (c *APIClient) GetVMTemplateByID(ctx context.Context, vmID uint64) (*proxmo
 	return nil, ErrVirtualMachineNotFound
 }
 
-// GetVMTemplatesByFilter returns a VM cluster resource by applying the provided filter functions.
-// nolint: dupl
-func (c *APIClient) GetVMTemplatesByFilter(ctx context.Context, filter ...func(*proxmox.ClusterResource) (bool, error)) (proxmox.ClusterResources, error) {
+// GetVMTemplatesByFilter returns the VM template cluster resources matching all the provided filter functions.
+// Use Or to match any of them.
+func (c *APIClient) GetVMTemplatesByFilter(ctx context.Context, filter ...ResourceFilter) (proxmox.ClusterResources, error) {
 	vmr, err := c.getResources(ctx, "vm")
 	if err != nil {
 		return nil, err
 	}
 
-	vms := proxmox.ClusterResources{}
-
-	for _, vm := range vmr {
-		if vm.Template == 0 {
-			continue
-		}
-
-		if vm.Type != "qemu" {
-			continue
-		}
-
-		if len(filter) == 0 {
-			vms = append(vms, vm)
-		}
-
-		for _, f := range filter {
-			ok, err := f(vm)
-			if err != nil {
-				return nil, err
-			}
-
-			if ok {
-				vms = append(vms, vm)
-			}
-		}
+	vms, err := filterItems(vmr, append([]ResourceFilter{isVMTemplate}, filter...)...)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(vms) > 0 {
@@ -222,26 +152,37 @@ func (c *APIClient) GetVMTemplateConfig(ctx context.Context, vmID int) (*proxmox
 	return vm, nil
 }
 
-// GetNextID retrieves the next available VM ID.
+// GetNextID retrieves the next available VM ID starting from vmid.
+// The IDs returned by the previous calls are skipped for the time set with WithVMIDTTL.
+// The search checks at most maxNextIDAttempts IDs and then fails with ErrNoFreeVMID.
+//
+// The returned ID is not reserved in the cluster, use VMIDAllocator to share the ID space
+// between several clients.
 func (c *APIClient) GetNextID(ctx context.Context, vmid int) (int, error) {
-	var ret string
+	for attempts := 0; attempts < maxNextIDAttempts && vmid <= maxVMID; vmid++ {
+		if _, found := c.lastVMID.Get(strconv.Itoa(vmid)); found {
+			continue
+		}
 
-	if _, found := c.lastVMID.Get(strconv.Itoa(vmid)); found {
-		return c.GetNextID(ctx, vmid+1)
-	}
+		attempts++
+
+		var ret string
+
+		data := make(map[string]interface{})
+		data["vmid"] = vmid
 
-	data := make(map[string]interface{})
-	data["vmid"] = vmid
+		if err := c.Client.GetWithParams(ctx, "/cluster/nextid", data, &ret); err != nil {
+			if errorIs(err, ErrConflict) {
+				continue
+			}
 
-	if err := c.Client.GetWithParams(ctx, "/cluster/nextid", data, &ret); err != nil {
-		if strings.HasPrefix(err.Error(), "bad request: 400 ") {
-			return c.GetNextID(ctx, vmid+1)
+			return 0, err
 		}
 
-		return 0, err
-	}
+		c.lastVMID.Set(strconv.Itoa(vmid), struct{}{}, c.vmidTTL)
 
-	c.lastVMID.SetDefault(strconv.Itoa(vmid), struct{}{})
+		return strconv.Atoi(ret)
+	}
 
-	return strconv.Atoi(ret)
+	return 0, ErrNoFreeVMID
 }
@@ -0,0 +1,400 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var backupArchiveRegexp = regexp.MustCompile(`creating (vzdump|Proxmox Backup Server) archive '([^']+)'`)
+
+// BackupOptions represents the options of a VM backup.
+type BackupOptions struct {
+	// Storage is the storage of the backup archive, it has to allow the backup content.
+	Storage string
+	// Mode is the backup mode, see proxmox.VirtualMachineBackupMode. The default mode is snapshot.
+	Mode string
+	// Compress is the compression of the archive, see proxmox.VirtualMachineBackupCompress.
+	Compress string
+	// NotesTemplate is the template of the backup notes,
+	// it can contain the {{cluster}}, {{guestname}}, {{node}} and {{vmid}} variables.
+	NotesTemplate string
+}
+
+// RestoreOptions represents the options of a VM restore.
+type RestoreOptions struct {
+	// Node is the node to restore the VM on.
+	// The default is the first node the storage of the archive is available on,
+	// set it for archives on node local storages.
+	Node string
+	// Storage is the storage of the restored disks. The disks are restored to their original storages if empty.
+	Storage string
+	// DiskStorages maps the disk devices (e.g. scsi1) to the storages they are moved to after the restore.
+	DiskStorages map[string]string
+	// Unique assigns new random MAC addresses to the network devices.
+	Unique bool
+}
+
+// VMBackup is a backup archive of a guest.
+type VMBackup struct {
+	VolID     string
+	Node      string
+	Storage   string
+	VMID      int
+	Format    string
+	Size      uint64
+	Notes     string
+	CTime     time.Time
+	Protected bool
+}
+
+// BackupVM creates a backup archive of the VM and returns it.
+func (c *APIClient) BackupVM(ctx context.Context, vmID int, options BackupOptions) (_ *VMBackup, err error) {
+	ctx, span := c.startSpan(ctx, "BackupVM", AttrVMID.Int(vmID), AttrStorage.String(options.Storage))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.BackupVMAsync(ctx, vmID, options)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	if err := h.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	// Other jobs may write backups of the VM at the same time, so the archive is taken from the task log.
+	lines, err := h.Log(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find backup of vm %d: %w", vmID, err)
+	}
+
+	volID, ok := backupVolID(options.Storage, lines)
+	if !ok {
+		return nil, fmt.Errorf("unable to find backup of vm %d in the task log: %w", vmID, ErrNotFound)
+	}
+
+	backups, err := c.listBackups(ctx, h.task.Node, options.Storage, vmID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find backup of vm %d: %w", vmID, err)
+	}
+
+	i := slices.IndexFunc(backups, func(b *VMBackup) bool { return b.VolID == volID })
+	if i < 0 {
+		return nil, fmt.Errorf("unable to find backup %s of vm %d: %w", volID, vmID, ErrNotFound)
+	}
+
+	return backups[i], nil
+}
+
+// backupVolID returns the volume ID of the archive the vzdump task log reports, e.g.
+// "INFO: creating vzdump archive '/var/lib/vz/dump/vzdump-qemu-100-2025_01_01-00_00_00.vma.zst'".
+// Proxmox Backup Server archives are reported with their snapshot name, e.g. 'vm/100/2025-01-01T00:00:00Z'.
+func backupVolID(storage string, lines []string) (string, bool) {
+	for _, line := range lines {
+		m := backupArchiveRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		name := m[2]
+		if m[1] == "vzdump" {
+			name = path.Base(name)
+		}
+
+		return fmt.Sprintf("%s:backup/%s", storage, name), true
+	}
+
+	return "", false
+}
+
+// BackupVMAsync creates a backup archive of the VM and returns the handle of the backup task.
+// The running tasks of the VM are handled according to WithTaskConflictPolicy.
+func (c *APIClient) BackupVMAsync(ctx context.Context, vmID int, options BackupOptions) (_ *TaskHandle, err error) {
+	if options.Storage == "" {
+		return nil, fmt.Errorf("backup storage is not set: %w", ErrBadRequest)
+	}
+
+	vmr, err := c.GetVMByID(ctx, uint64(vmID))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkTaskConflicts(ctx, vmr.Node, vmID, OperationBackup); err != nil {
+		return nil, err
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{vmr.Node}, c.storageSlots(ctx, vmr.Node, []string{options.Storage}))
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationBackup, vmID, "unable to backup virtual machine", release)
+	h.flush = false
+
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	params := map[string]any{
+		"vmid":    vmID,
+		"storage": options.Storage,
+	}
+
+	if options.Mode != "" {
+		params["mode"] = options.Mode
+	}
+
+	if options.Compress != "" {
+		params["compress"] = options.Compress
+	}
+
+	if options.NotesTemplate != "" {
+		params["notes-template"] = options.NotesTemplate
+	}
+
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/vzdump", vmr.Node), params, &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to backup vm %d: %w", vmID, err)
+	}
+
+	return h, nil
+}
+
+// ListBackups returns the backup archives of the guest on the storage, ordered by the creation time.
+// The zero vmID lists the archives of all guests. Node local storages are listed on every node.
+func (c *APIClient) ListBackups(ctx context.Context, storage string, vmID int) ([]*VMBackup, error) {
+	storages, err := c.GetClusterStoragesByFilter(ctx, func(r *proxmox.ClusterResource) (bool, error) {
+		return r.Storage == storage && r.Status == "available", nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(storages) == 0 {
+		return nil, ErrNotFound
+	}
+
+	backups := []*VMBackup{}
+
+	for _, st := range storages {
+		items, err := c.listBackups(ctx, st.Node, storage, vmID)
+		if err != nil {
+			return nil, err
+		}
+
+		backups = append(backups, items...)
+
+		if st.Shared == 1 {
+			break
+		}
+	}
+
+	sortBackups(backups)
+
+	return backups, nil
+}
+
+// listBackups returns the backup archives of the guest on the storage of the node, ordered by the creation time.
+func (c *APIClient) listBackups(ctx context.Context, node, storage string, vmID int) ([]*VMBackup, error) {
+	query := url.Values{"content": {"backup"}}
+	if vmID != 0 {
+		query.Set("vmid", strconv.Itoa(vmID))
+	}
+
+	content, err := c.getStorageContent(ctx, node, storage, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list backups on storage %s: %w", storage, err)
+	}
+
+	backups := make([]*VMBackup, 0, len(content))
+
+	for _, item := range content {
+		backups = append(backups, &VMBackup{
+			VolID:     item.Volid,
+			Node:      node,
+			Storage:   storage,
+			VMID:      int(item.VMID),
+			Format:    item.Format,
+			Size:      item.Size,
+			Notes:     item.Notes,
+			CTime:     time.Unix(int64(item.Ctime), 0),
+			Protected: bool(item.Protection),
+		})
+	}
+
+	sortBackups(backups)
+
+	return backups, nil
+}
+
+func sortBackups(backups []*VMBackup) {
+	slices.SortFunc(backups, func(a, b *VMBackup) int {
+		return cmp.Or(a.CTime.Compare(b.CTime), cmp.Compare(a.VolID, b.VolID), cmp.Compare(a.Node, b.Node))
+	})
+}
+
+// RestoreVM creates the VM newID from the backup archive, e.g. "backup:backup/vzdump-qemu-100-2025_01_01-00_00_00.vma.zst".
+func (c *APIClient) RestoreVM(ctx context.Context, archive string, newID int, options RestoreOptions) (err error) {
+	ctx, span := c.startSpan(ctx, "RestoreVM", AttrNode.String(options.Node), AttrVMID.Int(newID), attribute.String("proxmox.archive", archive))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.RestoreVMAsync(ctx, archive, newID, options)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// RestoreVMAsync creates the VM newID from the backup archive and returns the handle of the restore task.
+// Wait moves the disks of the DiskStorages option to their storages.
+func (c *APIClient) RestoreVMAsync(ctx context.Context, archive string, newID int, options RestoreOptions) (_ *TaskHandle, err error) {
+	node := options.Node
+	if node == "" {
+		storage, _, _ := strings.Cut(archive, ":")
+
+		nodes, err := c.GetNodesForStorage(ctx, storage)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find node for backup storage %s: %w", storage, err)
+		}
+
+		node = nodes[0]
+	}
+
+	storages := slices.Sorted(maps.Values(options.DiskStorages))
+	if options.Storage != "" {
+		storages = append(storages, options.Storage)
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{node}, c.storageSlots(ctx, node, storages))
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationRestore, newID, "unable to restore virtual machine", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	params := map[string]any{
+		"vmid":    newID,
+		"archive": archive,
+	}
+
+	if options.Storage != "" {
+		params["storage"] = options.Storage
+	}
+
+	if options.Unique {
+		params["unique"] = 1
+	}
+
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu", node), params, &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	}); err != nil {
+		return nil, fmt.Errorf("unable to restore vm %d from %s: %w", newID, archive, err)
+	}
+
+	if len(options.DiskStorages) > 0 {
+		h.then = func(ctx context.Context) error {
+			return c.moveRestoredDisks(ctx, node, newID, options.DiskStorages)
+		}
+	}
+
+	return h, nil
+}
+
+// moveRestoredDisks moves the disks of the restored VM to the storages.
+// The API does not accept disk options together with a backup archive, so the disks are moved after the restore.
+func (c *APIClient) moveRestoredDisks(ctx context.Context, node string, vmID int, storages map[string]string) error {
+	config := map[string]any{}
+	if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", node, vmID), &config); err != nil {
+		return fmt.Errorf("failed to get config of vm %d: %w", vmID, err)
+	}
+
+	for _, disk := range slices.Sorted(maps.Keys(storages)) {
+		value, ok := config[disk].(string)
+		if !ok {
+			return fmt.Errorf("disk %s of restored vm %d: %w", disk, vmID, ErrNotFound)
+		}
+
+		if strings.HasPrefix(value, storages[disk]+":") {
+			continue
+		}
+
+		moveCtx, moveSpan := c.startSpan(ctx, "vm.moveDisk", AttrVMID.Int(vmID), AttrStorage.String(storages[disk]), attribute.String("proxmox.disk", disk))
+		err := c.moveVMDisk(moveCtx, node, vmID, disk, storages[disk])
+		endSpan(moveSpan, err)
+
+		if err != nil {
+			return fmt.Errorf("failed to move disk %s of vm %d to storage %s: %w", disk, vmID, storages[disk], err)
+		}
+	}
+
+	return nil
+}
+
+// moveVMDisk moves the disk of the VM to the storage and deletes the source volume.
+func (c *APIClient) moveVMDisk(ctx context.Context, node string, vmID int, disk, storage string) error {
+	task, err := c.retryLocked(ctx, c.newLockRetry(), func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/move_disk", node, vmID), map[string]any{
+			"disk":    disk,
+			"storage": storage,
+			"delete":  1,
+		}, &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.waitTask(ctx, task, OperationDiskMove)
+}
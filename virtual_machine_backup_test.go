@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestBackupVM(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddBackupStorage("pve-1", "backup", true)
+	srv.AddBackupStorage("pve-2", "backup", true)
+	srv.AddVM("pve-1", 100, map[string]any{
+		"name":  "worker-1",
+		"scsi0": "rbd:vm-100-disk-0,size=4G",
+		"scsi1": "rbd:vm-100-disk-1,size=8G",
+		"net0":  "virtio=BC:24:11:00:01:00,bridge=vmbr0",
+	})
+
+	_, err := client.BackupVM(ctx, 100, goproxmox.BackupOptions{Storage: "local-lvm"})
+	assert.ErrorContains(t, err, "wrong content type")
+
+	_, err = client.BackupVM(ctx, 100, goproxmox.BackupOptions{})
+	assert.ErrorIs(t, err, goproxmox.ErrBadRequest)
+
+	first, err := client.BackupVM(ctx, 100, goproxmox.BackupOptions{Storage: "backup"})
+	require.NoError(t, err)
+
+	backup, err := client.BackupVM(ctx, 100, goproxmox.BackupOptions{
+		Storage:       "backup",
+		Mode:          "stop",
+		Compress:      "zstd",
+		NotesTemplate: "{{guestname}} ({{vmid}})",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, backup.VolID, "backup:backup/vzdump-qemu-100-")
+	assert.Equal(t, "pve-1", backup.Node)
+	assert.Equal(t, 100, backup.VMID)
+	assert.Equal(t, "vma.zst", backup.Format)
+	assert.Equal(t, "worker-1 (100)", backup.Notes)
+	assert.EqualValues(t, 12<<30, backup.Size)
+
+	backups, err := client.ListBackups(ctx, "backup", 100)
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, first.VolID, backups[0].VolID)
+	assert.Equal(t, backup.VolID, backups[1].VolID)
+
+	backups, err = client.ListBackups(ctx, "backup", 101)
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	_, err = client.ListBackups(ctx, "nfs", 100)
+	assert.ErrorIs(t, err, goproxmox.ErrNotFound)
+}
+
+func TestBackupVM_OtherJob(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddBackupStorage("pve-1", "backup", false)
+	srv.AddVM("pve-1", 100, map[string]any{
+		"name":  "worker-1",
+		"scsi0": "rbd:vm-100-disk-0,size=4G",
+	})
+
+	// Another job writes a newer backup of the VM.
+	other := srv.AddBackup("pve-1", "backup", 100, time.Now().Add(time.Hour))
+
+	backup, err := client.BackupVM(ctx, 100, goproxmox.BackupOptions{Storage: "backup"})
+	require.NoError(t, err)
+	assert.NotEqual(t, other, backup.VolID)
+	assert.Contains(t, backup.VolID, "backup:backup/vzdump-qemu-100-")
+	assert.EqualValues(t, 4<<30, backup.Size)
+}
+
+func TestRestoreVM(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddBackupStorage("pve-1", "backup", true)
+	srv.AddBackupStorage("pve-2", "backup", true)
+	srv.AddVM("pve-2", 100, map[string]any{
+		"name":  "worker-1",
+		"scsi0": "rbd:vm-100-disk-0,size=4G",
+		"scsi1": "rbd:vm-100-disk-1,size=8G",
+		"net0":  "virtio=BC:24:11:00:01:00,bridge=vmbr0",
+	})
+
+	backup, err := client.BackupVM(ctx, 100, goproxmox.BackupOptions{Storage: "backup"})
+	require.NoError(t, err)
+	assert.Equal(t, "pve-2", backup.Node)
+
+	err = client.RestoreVM(ctx, backup.VolID, 100, goproxmox.RestoreOptions{})
+	assert.ErrorIs(t, err, goproxmox.ErrConflict)
+
+	require.NoError(t, client.RestoreVM(ctx, backup.VolID, 200, goproxmox.RestoreOptions{
+		Storage:      "local-lvm",
+		DiskStorages: map[string]string{"scsi1": "rbd"},
+		Unique:       true,
+	}))
+	assert.Equal(t, []string{"qmrestore", "qmmove"}, lastTaskTypes(srv, 2))
+
+	vm, ok := srv.VM(200)
+	require.True(t, ok)
+	assert.Equal(t, "pve-1", vm.Node)
+	assert.Equal(t, "worker-1", vm.Config["name"])
+	assert.Equal(t, "local-lvm:vm-200-disk-0,size=4G", vm.Config["scsi0"])
+	assert.Equal(t, "rbd:vm-200-disk-0,size=8G", vm.Config["scsi1"])
+	assert.NotEqual(t, "virtio=BC:24:11:00:01:00,bridge=vmbr0", vm.Config["net0"])
+	assert.NotContains(t, vm.Config, "unused0")
+	assert.Equal(t, []string{"local-lvm:vm-200-disk-0"}, srv.Volumes("pve-1", "local-lvm"))
+
+	require.NoError(t, client.RestoreVM(ctx, backup.VolID, 201, goproxmox.RestoreOptions{Node: "pve-2"}))
+
+	vm, ok = srv.VM(201)
+	require.True(t, ok)
+	assert.Equal(t, "pve-2", vm.Node)
+	assert.Equal(t, "rbd:vm-201-disk-0,size=4G", vm.Config["scsi0"])
+	assert.Equal(t, "virtio=BC:24:11:00:01:00,bridge=vmbr0", vm.Config["net0"])
+}
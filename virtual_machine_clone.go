@@ -0,0 +1,177 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/luthermonson/go-proxmox"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TemplateReplicaFunc returns the filter which matches the replicas of the template on other nodes.
+type TemplateReplicaFunc func(template *proxmox.ClusterResource) ResourceFilter
+
+// TemplateReplicaByName matches the templates with the same name as the template.
+func TemplateReplicaByName(template *proxmox.ClusterResource) ResourceFilter {
+	return func(r *proxmox.ClusterResource) (bool, error) {
+		return r.Name == template.Name, nil
+	}
+}
+
+// TemplateReplicaByTag matches the templates which share a tag with the prefix with the template,
+// e.g. the prefix "template-" matches all the templates with the template-talos-1.9 tag.
+// Templates without such a tag have no replicas.
+func TemplateReplicaByTag(prefix string) TemplateReplicaFunc {
+	return func(template *proxmox.ClusterResource) ResourceFilter {
+		filters := []ResourceFilter{}
+
+		for _, tag := range strings.FieldsFunc(template.Tags, isTagSeparator) {
+			if strings.HasPrefix(tag, prefix) {
+				filters = append(filters, ByTag(tag))
+			}
+		}
+
+		return Or(filters...)
+	}
+}
+
+// clonePlan is the template and the nodes of a clone request.
+type clonePlan struct {
+	templateID int
+	// node is the node the clone task runs on.
+	node string
+	// targetNode is the node of the new VM.
+	targetNode string
+	// migrate is true if the new VM is migrated from the node to the target node after the clone.
+	migrate bool
+}
+
+// planClone selects the template to clone for the target node of the request.
+// A template on shared storages is cloned directly to the target node, a template on node local storages
+// from its replica on the target node. Without a replica the template is cloned on its node and migrated.
+func (c *APIClient) planClone(ctx context.Context, templateID int, options VMCloneRequest) (clonePlan, error) {
+	plan := clonePlan{templateID: templateID, node: options.Node, targetNode: cmp.Or(options.TargetNode, options.Node)}
+	if plan.node != "" && plan.targetNode == plan.node {
+		return plan, nil
+	}
+
+	template, err := c.GetVMTemplateByID(ctx, uint64(templateID))
+	if err != nil {
+		return plan, fmt.Errorf("unable to find vm template %d: %w", templateID, err)
+	}
+
+	plan.node = cmp.Or(options.Node, template.Node)
+	plan.targetNode = cmp.Or(options.TargetNode, plan.node)
+
+	if plan.targetNode == plan.node {
+		return plan, nil
+	}
+
+	cfg := &proxmox.VirtualMachineConfig{}
+	if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", plan.node, templateID), cfg); err != nil {
+		return plan, fmt.Errorf("failed to get config of vm template %d: %w", templateID, err)
+	}
+
+	storages := getVMStorages(cfg)
+	if options.Storage != "" {
+		storages = append(storages, options.Storage)
+	}
+
+	shared, err := c.sharedStorages(ctx, plan.targetNode, storages)
+	if err != nil {
+		return plan, err
+	}
+
+	if shared {
+		return plan, nil
+	}
+
+	replicas, err := c.GetVMTemplatesByFilter(ctx, ByNode(plan.targetNode), c.templateReplicas(template))
+	if err != nil && !errors.Is(err, ErrVirtualMachineTemplateNotFound) {
+		return plan, err
+	}
+
+	if len(replicas) > 0 {
+		trace.SpanFromContext(ctx).AddEvent("proxmox.clone.replica", trace.WithAttributes(
+			attribute.Int("proxmox.template_vmid", int(replicas[0].VMID)),
+		))
+
+		plan.templateID = int(replicas[0].VMID)
+		plan.node = plan.targetNode
+
+		return plan, nil
+	}
+
+	trace.SpanFromContext(ctx).AddEvent("proxmox.clone.migrate")
+
+	plan.migrate = true
+
+	return plan, nil
+}
+
+// sharedStorages reports whether all the storages are shared and available on the node.
+func (c *APIClient) sharedStorages(ctx context.Context, node string, storages []string) (bool, error) {
+	for _, storage := range storages {
+		res, err := c.GetClusterStoragesByFilter(ctx, ByNode(node), func(r *proxmox.ClusterResource) (bool, error) {
+			return r.Storage == storage && r.Shared == 1 && r.Status == "available", nil
+		})
+		if err != nil {
+			return false, err
+		}
+
+		if len(res) == 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// migrateClonedVM migrates the stopped VM with its local disks to the target node and storage.
+// The storage slots of the target storage are taken with the clone by CloneVMAsync.
+func (c *APIClient) migrateClonedVM(ctx context.Context, node string, vmID int, targetNode, targetStorage string) (err error) {
+	ctx, span := c.startSpan(ctx, "vm.migrate", AttrNode.String(node), AttrVMID.Int(vmID), attribute.String("proxmox.target_node", targetNode))
+	defer func() { endSpan(span, err) }()
+
+	params := migrateVMOptions{withLocalDisks: true, targetStorage: targetStorage}.params(targetNode)
+
+	task, err := c.retryLocked(ctx, c.newLockRetry(), func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/migrate", node, vmID), params, &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to migrate vm %d to node %s: %w", vmID, targetNode, err)
+	}
+
+	if err := c.waitTask(ctx, task, OperationMigrate); err != nil {
+		return fmt.Errorf("unable to migrate vm %d to node %s: %w", vmID, targetNode, err)
+	}
+
+	c.flushResources("vm")
+
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+	"github.com/sergelogvinov/go-proxmox/goproxmoxtest"
+)
+
+// lastTaskID returns the ID of the last task of the type.
+func lastTaskID(srv *goproxmoxtest.Server, typ string) string {
+	tasks := srv.Tasks()
+	for i := len(tasks) - 1; i >= 0; i-- {
+		if tasks[i].Type == typ {
+			return tasks[i].ID
+		}
+	}
+
+	return ""
+}
+
+func TestCloneVM_TargetNode(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddTemplate("pve-1", 9100, map[string]any{"name": "shared", "scsi0": "rbd:base-9100-disk-0,size=2G"})
+
+	// The template on shared storage is cloned directly to the target node.
+	id, err := client.CloneVM(ctx, 9100, goproxmox.VMCloneRequest{TargetNode: "pve-2", NewID: 100, Name: "worker-1"})
+	require.NoError(t, err)
+	assert.Equal(t, 100, id)
+	assert.Equal(t, []string{"qmclone", "qmconfig"}, lastTaskTypes(srv, 2))
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "pve-2", vm.Node)
+	assert.Equal(t, "worker-1", vm.Config["name"])
+
+	// The template on local storage is cloned on its node and migrated without a replica.
+	_, err = client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", TargetNode: "pve-2", NewID: 101, Name: "worker-2"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"qmclone", "qmigrate", "qmconfig"}, lastTaskTypes(srv, 3))
+
+	vm, ok = srv.VM(101)
+	require.True(t, ok)
+	assert.Equal(t, "pve-2", vm.Node)
+	assert.Equal(t, "local-lvm:vm-101-disk-0,size=2G", vm.Config["scsi0"])
+	assert.Equal(t, []string{"local-lvm:vm-101-disk-0"}, srv.Volumes("pve-2", "local-lvm"))
+
+	// The replica of the template on the target node is cloned instead.
+	srv.AddTemplate("pve-2", 9001, map[string]any{"name": "template", "scsi0": "local-lvm:base-9001-disk-0,size=2G"})
+
+	_, err = client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", TargetNode: "pve-2", NewID: 102, Name: "worker-3"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "9001", lastTaskID(srv, "qmclone"))
+
+	vm, ok = srv.VM(102)
+	require.True(t, ok)
+	assert.Equal(t, "pve-2", vm.Node)
+	assert.Equal(t, "worker-3", vm.Config["name"])
+}
+
+func TestCloneVM_TemplateReplicaByTag(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithTemplateReplicas(goproxmox.TemplateReplicaByTag("template-")))
+	ctx := context.Background()
+
+	srv.AddTemplate("pve-1", 9100, map[string]any{"name": "talos", "tags": "prod;template-talos-1.9", "scsi0": "local-lvm:base-9100-disk-0,size=2G"})
+	srv.AddTemplate("pve-2", 9101, map[string]any{"name": "talos-old", "tags": "template-talos-1.8", "scsi0": "local-lvm:base-9101-disk-0,size=2G"})
+	srv.AddTemplate("pve-2", 9102, map[string]any{"name": "talos-pve-2", "tags": "template-talos-1.9", "scsi0": "local-lvm:base-9102-disk-0,size=2G"})
+
+	_, err := client.CloneVM(ctx, 9100, goproxmox.VMCloneRequest{TargetNode: "pve-2", NewID: 100, Name: "worker-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "9102", lastTaskID(srv, "qmclone"))
+
+	// The template without the tag has no replicas, even with the same name.
+	_, err = client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{TargetNode: "pve-2", NewID: 101, Name: "worker-2"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"qmclone", "qmigrate", "qmconfig"}, lastTaskTypes(srv, 3))
+}
+
+func TestCloneVMAsync_CloneError(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "scsi0": "rbd:vm-100-disk-0,size=1G"})
+
+	h, err := client.CloneVMAsync(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-2"})
+	require.Error(t, err)
+	assert.Nil(t, h)
+
+	var cloneErr *goproxmox.CloneError
+	require.ErrorAs(t, err, &cloneErr)
+	assert.Equal(t, 100, cloneErr.VMID)
+
+	id, err := client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-2"})
+	require.ErrorAs(t, err, &cloneErr)
+	assert.Equal(t, 100, id)
+}
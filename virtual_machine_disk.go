@@ -24,7 +24,16 @@ import (
 )
 
 // CreateVMDisk creates a new disk for the virtual machine.
-func (c *APIClient) CreateVMDisk(ctx context.Context, vmid int, node string, storage string, disk string, sizeBytes int64) (string, error) {
+func (c *APIClient) CreateVMDisk(ctx context.Context, vmid int, node string, storage string, disk string, sizeBytes int64) (_ string, err error) {
+	ctx, span := c.startSpan(ctx, "CreateVMDisk", AttrNode.String(node), AttrVMID.Int(vmid), AttrStorage.String(storage))
+	defer func() { endSpan(span, err) }()
+
+	release, err := c.limiter.acquire(ctx, []string{node}, c.storageSlots(ctx, node, []string{storage}))
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
 	params := make(map[string]any)
 	params["vmid"] = vmid
 	params["node"] = node
@@ -33,7 +42,7 @@ func (c *APIClient) CreateVMDisk(ctx context.Context, vmid int, node string, sto
 	params["size"] = fmt.Sprintf("%d", sizeBytes/1024)
 	name := ""
 
-	err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/storage/%s/content", node, storage), params, &name)
+	err = c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/storage/%s/content", node, storage), params, &name)
 	if err != nil {
 		return name, fmt.Errorf("unable to create disk for virtual machine: %w", err)
 	}
@@ -42,38 +51,81 @@ func (c *APIClient) CreateVMDisk(ctx context.Context, vmid int, node string, sto
 }
 
 // DeleteVMDisk deletes a disk from the virtual machine.
-func (c *APIClient) DeleteVMDisk(ctx context.Context, node string, storage string, disk string) error {
-	var upid proxmox.UPID
-	if err := c.Client.Delete(ctx, fmt.Sprintf("/nodes/%s/storage/%s/content/%s", node, storage, disk), &upid); err != nil {
+func (c *APIClient) DeleteVMDisk(ctx context.Context, node string, storage string, disk string) (err error) {
+	ctx, span := c.startSpan(ctx, "DeleteVMDisk", AttrNode.String(node), AttrStorage.String(storage))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.DeleteVMDiskAsync(ctx, node, storage, disk)
+	if err != nil {
 		return err
 	}
+	defer h.Close()
 
-	task := proxmox.NewTask(upid, c.Client)
-	if task != nil {
-		if err := task.WaitFor(ctx, 30); err != nil {
-			return fmt.Errorf("unable to delete virtual machine disk: %w", err)
-		}
+	return h.Wait(ctx)
+}
+
+// DeleteVMDiskAsync deletes a disk from the storage and returns the handle of the delete task.
+func (c *APIClient) DeleteVMDiskAsync(ctx context.Context, node string, storage string, disk string) (_ *TaskHandle, err error) {
+	release, err := c.limiter.acquire(ctx, []string{node}, c.storageSlots(ctx, node, []string{storage}))
+	if err != nil {
+		return nil, err
+	}
 
-		if task.IsFailed {
-			return fmt.Errorf("unable to delete virtual machine disk: %s", task.ExitStatus)
+	h := c.newDiskTaskHandle(OperationDiskDelete, 0, "unable to delete virtual machine disk", release)
+	defer func() {
+		if err != nil {
+			h.finish()
 		}
+	}()
+
+	var upid proxmox.UPID
+	if err := c.Client.Delete(ctx, fmt.Sprintf("/nodes/%s/storage/%s/content/%s", node, storage, disk), &upid); err != nil {
+		return nil, err
 	}
 
-	return nil
+	h.setTask(proxmox.NewTask(upid, c.Client))
+
+	return h, nil
 }
 
 // AttachVMDisk attaches an existing disk to the virtual machine.
-func (c *APIClient) AttachVMDisk(ctx context.Context, vmID int, device, disk string) error {
-	vmr, err := c.GetVMByID(ctx, uint64(vmID))
+func (c *APIClient) AttachVMDisk(ctx context.Context, vmID int, device, disk string) (err error) {
+	ctx, span := c.startSpan(ctx, "AttachVMDisk", AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.AttachVMDiskAsync(ctx, vmID, device, disk)
 	if err != nil {
 		return err
 	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// AttachVMDiskAsync attaches an existing disk to the virtual machine and returns the handle of the config task.
+func (c *APIClient) AttachVMDiskAsync(ctx context.Context, vmID int, device, disk string) (_ *TaskHandle, err error) {
+	vmr, err := c.GetVMByID(ctx, uint64(vmID))
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{vmr.Node}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newDiskTaskHandle(OperationConfig, vmID, "unable to attach virtual machine disk", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
 
 	vm := &proxmox.VirtualMachine{}
 	vm.New(c.Client, vmr.Node, vmID)
 
 	if err := vm.Ping(ctx); err != nil {
-		return err
+		return nil, err
 	}
 
 	vmOptions := proxmox.VirtualMachineOption{
@@ -81,81 +133,112 @@ func (c *APIClient) AttachVMDisk(ctx context.Context, vmID int, device, disk str
 		Value: disk,
 	}
 
-	task, err := vm.Config(ctx, vmOptions)
-	if err != nil {
-		return fmt.Errorf("unable to attach disk: %v, options=%+v", err, vmOptions)
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		return vm.Config(ctx, vmOptions)
+	}); err != nil {
+		return nil, fmt.Errorf("unable to attach disk: %w, options=%+v", err, vmOptions)
 	}
 
-	if task != nil {
-		if err = task.WaitFor(ctx, 5*60); err != nil {
-			return fmt.Errorf("unable to attach virtual machine disk: %w", err)
-		}
+	return h, nil
+}
 
-		if task.IsFailed {
-			return fmt.Errorf("unable to attach virtual machine disk: %s", task.ExitStatus)
-		}
+// DetachVMDisk detaches a disk from the virtual machine.
+func (c *APIClient) DetachVMDisk(ctx context.Context, vmID int, device string) (err error) {
+	ctx, span := c.startSpan(ctx, "DetachVMDisk", AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.DetachVMDiskAsync(ctx, vmID, device)
+	if err != nil {
+		return err
 	}
+	defer h.Close()
 
-	return nil
+	return h.Wait(ctx)
 }
 
-// DetachVMDisk detaches a disk from the virtual machine.
-func (c *APIClient) DetachVMDisk(ctx context.Context, vmID int, device string) error {
+// DetachVMDiskAsync detaches a disk from the virtual machine and returns the handle of the unlink task.
+func (c *APIClient) DetachVMDiskAsync(ctx context.Context, vmID int, device string) (_ *TaskHandle, err error) {
 	vmr, err := c.GetVMByID(ctx, uint64(vmID))
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{vmr.Node}, nil)
+	if err != nil {
+		return nil, err
 	}
 
+	h := c.newDiskTaskHandle(OperationConfig, vmID, "unable to detach virtual machine disk", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
 	vm := &proxmox.VirtualMachine{}
 	vm.New(c.Client, vmr.Node, vmID)
 
 	if err := vm.Ping(ctx); err != nil {
-		return err
+		return nil, err
 	}
 
-	task, err := vm.UnlinkDisk(ctx, device, false)
-	if err != nil {
-		return fmt.Errorf("failed to unlink disk: %v", err)
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		return vm.UnlinkDisk(ctx, device, false)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to unlink disk: %w", err)
 	}
 
-	if task != nil {
-		if err := task.WaitFor(ctx, 5*60); err != nil {
-			return fmt.Errorf("unable to detach virtual machine disk: %w", err)
-		}
+	return h, nil
+}
 
-		if task.IsFailed {
-			return fmt.Errorf("unable to detach virtual machine disk: %s", task.ExitStatus)
-		}
+// ResizeVMDisk resizes a disk for the virtual machine.
+func (c *APIClient) ResizeVMDisk(ctx context.Context, vmID int, node, disk, size string) (err error) {
+	ctx, span := c.startSpan(ctx, "ResizeVMDisk", AttrNode.String(node), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.ResizeVMDiskAsync(ctx, vmID, node, disk, size)
+	if err != nil {
+		return err
 	}
+	defer h.Close()
 
-	return nil
+	return h.Wait(ctx)
 }
 
-// ResizeVMDisk resizes a disk for the virtual machine.
-func (c *APIClient) ResizeVMDisk(ctx context.Context, vmID int, node, disk, size string) error {
+// ResizeVMDiskAsync resizes a disk for the virtual machine and returns the handle of the resize task.
+func (c *APIClient) ResizeVMDiskAsync(ctx context.Context, vmID int, node, disk, size string) (_ *TaskHandle, err error) {
 	vm := &proxmox.VirtualMachine{}
 	vm.New(c.Client, node, vmID)
 
 	if err := vm.Ping(ctx); err != nil {
-		return err
+		return nil, err
 	}
 
-	task, err := vm.ResizeDisk(ctx, disk, size)
+	release, err := c.limiter.acquire(ctx, []string{node}, nil)
 	if err != nil {
-		return fmt.Errorf("unable to resize virtual machine disk: %w", err)
+		return nil, err
 	}
 
-	if task == nil {
-		return nil
-	}
+	h := c.newDiskTaskHandle(OperationDiskResize, vmID, "unable to resize virtual machine disk", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
 
-	if err := task.WaitFor(ctx, 5*60); err != nil {
-		return fmt.Errorf("unable to resize virtual machine disk: %w", err)
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		return vm.ResizeDisk(ctx, disk, size)
+	}); err != nil {
+		return nil, fmt.Errorf("unable to resize virtual machine disk: %w", err)
 	}
 
-	if task.IsFailed {
-		return fmt.Errorf("unable to resize virtual machine disk: %s", task.ExitStatus)
-	}
+	return h, nil
+}
+
+// newDiskTaskHandle returns a handle of a disk task, which does not change the cluster resources.
+func (c *APIClient) newDiskTaskHandle(op Operation, vmid int, desc string, release func()) *TaskHandle {
+	h := c.newTaskHandle(op, vmid, desc, release)
+	h.flush = false
 
-	return nil
+	return h
 }
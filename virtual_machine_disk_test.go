@@ -0,0 +1,50 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVMDiskLifecycle(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	disk, err := client.CreateVMDisk(ctx, 100, "pve-1", "local-lvm", "vm-100-disk-1", 1<<30)
+	require.NoError(t, err)
+	assert.Equal(t, "local-lvm:vm-100-disk-1", disk)
+
+	require.NoError(t, client.AttachVMDisk(ctx, 100, "scsi1", disk))
+	require.NoError(t, client.ResizeVMDisk(ctx, 100, "pve-1", "scsi1", "2G"))
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "local-lvm:vm-100-disk-1,size=2G", vm.Config["scsi1"])
+
+	require.NoError(t, client.DetachVMDisk(ctx, 100, "scsi1"))
+	require.NoError(t, client.DeleteVMDisk(ctx, "pve-1", "local-lvm", "vm-100-disk-1"))
+
+	assert.Empty(t, srv.Volumes("pve-1", "local-lvm"))
+}
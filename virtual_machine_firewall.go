@@ -25,7 +25,10 @@ import (
 )
 
 // CreateVMFirewallRules creates firewall rules for the specified virtual machine.
-func (c *APIClient) CreateVMFirewallRules(ctx context.Context, vmID int, nodeName string, rules []*proxmox.FirewallRule) error {
+func (c *APIClient) CreateVMFirewallRules(ctx context.Context, vmID int, nodeName string, rules []*proxmox.FirewallRule) (err error) {
+	ctx, span := c.startSpan(ctx, "CreateVMFirewallRules", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
 	node, err := c.Node(ctx, nodeName)
 	if err != nil {
 		return fmt.Errorf("unable to find node with name %s: %w", nodeName, err)
@@ -39,7 +42,7 @@ func (c *APIClient) CreateVMFirewallRules(ctx context.Context, vmID int, nodeNam
 	if len(rules) > 0 {
 		vmOptions, err := vm.FirewallOptionGet(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to get firewall options for vm %d: %v", vmID, err)
+			return fmt.Errorf("failed to get firewall options for vm %d: %w", vmID, err)
 		}
 
 		if vmOptions == nil {
@@ -56,12 +59,12 @@ func (c *APIClient) CreateVMFirewallRules(ctx context.Context, vmID int, nodeNam
 		vmOptions.PolicyIn = "DROP"
 
 		if err := vm.FirewallOptionSet(ctx, vmOptions); err != nil {
-			return fmt.Errorf("failed to set firewall options for vm %d: %v", vmID, err)
+			return fmt.Errorf("failed to set firewall options for vm %d: %w", vmID, err)
 		}
 
 		for _, rule := range rules {
 			if err := vm.FirewallRulesCreate(ctx, rule); err != nil {
-				return fmt.Errorf("failed to set firewall rule for vm %d: %v", vmID, err)
+				return fmt.Errorf("failed to set firewall rule for vm %d: %w", vmID, err)
 			}
 		}
 	}
@@ -70,7 +73,10 @@ func (c *APIClient) CreateVMFirewallRules(ctx context.Context, vmID int, nodeNam
 }
 
 // UpdateVMFirewallRules updates firewall rules for the specified virtual machine.
-func (c *APIClient) UpdateVMFirewallRules(ctx context.Context, vmID int, nodeName string, rules []*proxmox.FirewallRule) error {
+func (c *APIClient) UpdateVMFirewallRules(ctx context.Context, vmID int, nodeName string, rules []*proxmox.FirewallRule) (err error) {
+	ctx, span := c.startSpan(ctx, "UpdateVMFirewallRules", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
 	node, err := c.Node(ctx, nodeName)
 	if err != nil {
 		return fmt.Errorf("unable to find node with name %s: %w", nodeName, err)
@@ -83,7 +89,7 @@ func (c *APIClient) UpdateVMFirewallRules(ctx context.Context, vmID int, nodeNam
 
 	oldRules, err := vm.FirewallGetRules(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get firewall rules for vm %d: %v", vmID, err)
+		return fmt.Errorf("failed to get firewall rules for vm %d: %w", vmID, err)
 	}
 
 	n := len(oldRules)
@@ -95,15 +101,15 @@ func (c *APIClient) UpdateVMFirewallRules(ctx context.Context, vmID int, nodeNam
 		switch {
 		case i < len(oldRules) && i < len(rules) && !reflect.DeepEqual(oldRules[i], rules[i]):
 			if err := vm.FirewallRulesUpdate(ctx, rules[i]); err != nil {
-				return fmt.Errorf("failed to update firewall rule for vm %d: %v", vmID, err)
+				return fmt.Errorf("failed to update firewall rule for vm %d: %w", vmID, err)
 			}
 		case i < len(oldRules) && i >= len(rules):
 			if err := vm.FirewallRulesDelete(ctx, i); err != nil {
-				return fmt.Errorf("failed to delete old firewall rule for vm %d: %v", vmID, err)
+				return fmt.Errorf("failed to delete old firewall rule for vm %d: %w", vmID, err)
 			}
 		case i >= len(oldRules) && i < len(rules):
 			if err := vm.FirewallRulesCreate(ctx, rules[i]); err != nil {
-				return fmt.Errorf("failed to create new firewall rule for vm %d: %v", vmID, err)
+				return fmt.Errorf("failed to create new firewall rule for vm %d: %w", vmID, err)
 			}
 		}
 	}
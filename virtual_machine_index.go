@@ -0,0 +1,248 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/luthermonson/go-proxmox"
+	"golang.org/x/sync/errgroup"
+)
+
+// vmIndexConcurrency is the number of VM configs fetched at once.
+const vmIndexConcurrency = 8
+
+// vmIndex maps the SMBIOS identity of the VMs to their cluster resources.
+// It is refreshed incrementally from the cluster resources: only the configs of the new VMs,
+// of the VMs whose resource changed and of the VMs changed by the client tasks are fetched.
+type vmIndex struct {
+	mu      sync.Mutex
+	entries map[uint64]*vmIndexEntry
+	byUUID  map[string]*vmIndexEntry
+	byName  map[string]*vmIndexEntry
+
+	// fetches holds the sequence number of the config fetch in flight by VM ID.
+	// forget drops it, so a config fetched before the VM changed is not indexed.
+	fetches map[uint64]uint64
+	seq     uint64
+}
+
+type vmIndexEntry struct {
+	vm   *proxmox.ClusterResource
+	uuid string
+	name string
+	// state is the resource state the config was fetched for.
+	state vmResourceState
+}
+
+// vmResourceState is the part of a VM cluster resource which changes with the VM config.
+type vmResourceState struct {
+	node     string
+	name     string
+	tags     string
+	maxCPU   uint64
+	maxMem   uint64
+	maxDisk  uint64
+	template uint64
+}
+
+func newVMResourceState(vm *proxmox.ClusterResource) vmResourceState {
+	return vmResourceState{
+		node:     vm.Node,
+		name:     vm.Name,
+		tags:     vm.Tags,
+		maxCPU:   vm.MaxCPU,
+		maxMem:   vm.MaxMem,
+		maxDisk:  vm.MaxDisk,
+		template: vm.Template,
+	}
+}
+
+func newVMIndex() *vmIndex {
+	return &vmIndex{
+		entries: map[uint64]*vmIndexEntry{},
+		byUUID:  map[string]*vmIndexEntry{},
+		byName:  map[string]*vmIndexEntry{},
+		fetches: map[uint64]uint64{},
+	}
+}
+
+// GetVMByUUID returns a VM cluster resource by its SMBIOS UUID, which is the system UUID of the guest.
+func (c *APIClient) GetVMByUUID(ctx context.Context, uuid string) (*proxmox.ClusterResource, error) {
+	return c.getVMByIndex(ctx, func(idx *vmIndex) *vmIndexEntry {
+		return idx.byUUID[strings.ToLower(uuid)]
+	})
+}
+
+// GetVMBySerialName returns a VM cluster resource by the instance name in its SMBIOS serial, see CloneVM.
+func (c *APIClient) GetVMBySerialName(ctx context.Context, name string) (*proxmox.ClusterResource, error) {
+	return c.getVMByIndex(ctx, func(idx *vmIndex) *vmIndexEntry {
+		return idx.byName[name]
+	})
+}
+
+func (c *APIClient) getVMByIndex(ctx context.Context, lookup func(*vmIndex) *vmIndexEntry) (*proxmox.ClusterResource, error) {
+	vms, err := c.GetVMsByFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.vmIndex.sync(ctx, c, vms)
+
+	c.vmIndex.mu.Lock()
+	defer c.vmIndex.mu.Unlock()
+
+	if e := lookup(c.vmIndex); e != nil {
+		return e.vm, nil
+	}
+
+	return nil, ErrVirtualMachineNotFound
+}
+
+type vmIndexFetch struct {
+	vm    *proxmox.ClusterResource
+	seq   uint64
+	entry *vmIndexEntry
+}
+
+// sync indexes the configs of the new and changed VMs and drops the deleted VMs.
+// The configs are fetched without holding the lock. The VMs whose config can't be fetched,
+// e.g. the unreachable ones, are left out of the index until a later sync fetches them.
+func (idx *vmIndex) sync(ctx context.Context, c *APIClient, vms proxmox.ClusterResources) {
+	fetches := idx.update(vms)
+	if len(fetches) == 0 {
+		return
+	}
+
+	var g errgroup.Group
+
+	g.SetLimit(vmIndexConcurrency)
+
+	for _, f := range fetches {
+		g.Go(func() error {
+			cfg := proxmox.VirtualMachineConfig{}
+			if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", f.vm.Node, f.vm.VMID), &cfg); err != nil {
+				return nil //nolint:nilerr
+			}
+
+			smbios1 := VMSMBIOS{}
+			smbios1.UnmarshalString(cfg.SMBios1) //nolint:errcheck
+
+			f.entry = &vmIndexEntry{
+				vm:    f.vm,
+				uuid:  strings.ToLower(smbios1.UUID),
+				name:  parseSMBIOSSerialName(smbios1),
+				state: newVMResourceState(f.vm),
+			}
+
+			return nil
+		})
+	}
+
+	g.Wait() //nolint:errcheck
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, f := range fetches {
+		if idx.fetches[f.vm.VMID] != f.seq {
+			continue
+		}
+
+		delete(idx.fetches, f.vm.VMID)
+
+		if f.entry != nil {
+			idx.add(f.vm.VMID, f.entry)
+		}
+	}
+}
+
+// update updates the indexed resources, drops the deleted VMs and returns the configs to fetch.
+func (idx *vmIndex) update(vms proxmox.ClusterResources) []*vmIndexFetch {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	current := make(map[uint64]struct{}, len(vms))
+	fetches := []*vmIndexFetch{}
+
+	for _, vm := range vms {
+		current[vm.VMID] = struct{}{}
+
+		if e, ok := idx.entries[vm.VMID]; ok && e.state == newVMResourceState(vm) {
+			e.vm = vm
+
+			continue
+		}
+
+		if vm.Status != "unknown" {
+			idx.seq++
+			idx.fetches[vm.VMID] = idx.seq
+
+			fetches = append(fetches, &vmIndexFetch{vm: vm, seq: idx.seq})
+		}
+	}
+
+	for vmid := range idx.entries {
+		if _, ok := current[vmid]; !ok {
+			idx.remove(vmid)
+		}
+	}
+
+	return fetches
+}
+
+func (idx *vmIndex) add(vmid uint64, e *vmIndexEntry) {
+	idx.remove(vmid)
+	idx.entries[vmid] = e
+
+	if e.uuid != "" {
+		idx.byUUID[e.uuid] = e
+	}
+
+	if e.name != "" {
+		idx.byName[e.name] = e
+	}
+}
+
+func (idx *vmIndex) remove(vmid uint64) {
+	e, ok := idx.entries[vmid]
+	if !ok {
+		return
+	}
+
+	delete(idx.entries, vmid)
+
+	if idx.byUUID[e.uuid] == e {
+		delete(idx.byUUID, e.uuid)
+	}
+
+	if idx.byName[e.name] == e {
+		delete(idx.byName, e.name)
+	}
+}
+
+// forget drops the VM from the index, so its config is fetched again by the next lookup.
+func (idx *vmIndex) forget(vmid int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(uint64(vmid))
+	delete(idx.fetches, uint64(vmid))
+}
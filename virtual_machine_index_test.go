@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func smbios(uuid, name string, vmid int) string {
+	serial := base64.StdEncoding.EncodeToString(fmt.Appendf(nil, "h=%s;i=%d", name, vmid))
+
+	return fmt.Sprintf("base64=1,serial=%s,uuid=%s", serial, uuid)
+}
+
+func TestGetVMByUUID(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithCache(goproxmox.NoopCache{}))
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "smbios1": smbios("6f1f0c2e-3a4b-4c5d-8e9f-0a1b2c3d4e5f", "worker-1", 100)})
+	srv.AddVM("pve-2", 101, map[string]any{"name": "worker-2", "smbios1": smbios("a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", "worker-2", 101)})
+
+	vm, err := client.GetVMByUUID(ctx, "6F1F0C2E-3A4B-4C5D-8E9F-0A1B2C3D4E5F")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), vm.VMID)
+
+	vm, err = client.GetVMBySerialName(ctx, "worker-2")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(101), vm.VMID)
+	assert.Equal(t, "pve-2", vm.Node)
+
+	_, err = client.GetVMBySerialName(ctx, "worker-3")
+	require.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+
+	assert.Equal(t, 1, srv.RequestCount(http.MethodGet, "/nodes/pve-1/qemu/100/config"))
+	assert.Equal(t, 1, srv.RequestCount(http.MethodGet, "/nodes/pve-2/qemu/101/config"))
+
+	srv.AddVM("pve-1", 102, map[string]any{"name": "worker-3", "smbios1": smbios("0d9c8b7a-6f5e-4d3c-2b1a-0f9e8d7c6b5a", "worker-3", 102)})
+
+	vm, err = client.GetVMBySerialName(ctx, "worker-3")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(102), vm.VMID)
+
+	assert.Equal(t, 1, srv.RequestCount(http.MethodGet, "/nodes/pve-1/qemu/100/config"))
+	assert.Equal(t, 1, srv.RequestCount(http.MethodGet, "/nodes/pve-1/qemu/102/config"))
+
+	require.NoError(t, client.DeleteVMByID(ctx, "pve-1", 100))
+
+	_, err = client.GetVMByUUID(ctx, "6f1f0c2e-3a4b-4c5d-8e9f-0a1b2c3d4e5f")
+	require.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+}
+
+// failingTransport fails the requests of the path while it is set.
+type failingTransport struct {
+	path atomic.Value
+}
+
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if path, _ := t.path.Load().(string); path != "" && strings.HasSuffix(req.URL.Path, path) {
+		return &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Status:     "500 Internal Server Error",
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"data":null}`)),
+			Request:    req,
+		}, nil
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestGetVMByUUID_ConfigError(t *testing.T) {
+	t.Parallel()
+
+	tr := &failingTransport{}
+	tr.path.Store("/qemu/101/config")
+
+	srv, client := newTestCluster(t, goproxmox.WithCache(goproxmox.NoopCache{}), goproxmox.WithHTTPClient(&http.Client{Transport: tr}))
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "smbios1": smbios("6f1f0c2e-3a4b-4c5d-8e9f-0a1b2c3d4e5f", "worker-1", 100)})
+	srv.AddVM("pve-2", 101, map[string]any{"name": "worker-2", "smbios1": smbios("a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", "worker-2", 101)})
+
+	// The VM whose config can't be fetched is left out of the index.
+	vm, err := client.GetVMBySerialName(ctx, "worker-1")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), vm.VMID)
+
+	_, err = client.GetVMBySerialName(ctx, "worker-2")
+	require.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+
+	tr.path.Store("")
+
+	vm, err = client.GetVMBySerialName(ctx, "worker-2")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(101), vm.VMID)
+
+	assert.Equal(t, 1, srv.RequestCount(http.MethodGet, "/nodes/pve-1/qemu/100/config"))
+	assert.Equal(t, 1, srv.RequestCount(http.MethodGet, "/nodes/pve-2/qemu/101/config"))
+}
+
+func TestGetVMByUUID_ResourceChange(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithCache(goproxmox.NoopCache{}))
+	ctx := context.Background()
+
+	other, err := goproxmox.NewAPIClientWithOptions(srv.URL(), goproxmox.WithTaskPollInterval(10*time.Millisecond))
+	require.NoError(t, err)
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "smbios1": smbios("6f1f0c2e-3a4b-4c5d-8e9f-0a1b2c3d4e5f", "worker-1", 100)})
+	srv.AddVM("pve-2", 101, map[string]any{"name": "worker-2", "smbios1": smbios("a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", "worker-2", 101)})
+
+	_, err = client.GetVMBySerialName(ctx, "worker-1")
+	require.NoError(t, err)
+
+	// Another client renames the VM, only its config is fetched again.
+	require.NoError(t, other.UpdateVMByID(ctx, "pve-2", 101, map[string]any{
+		"name":    "worker-3",
+		"smbios1": smbios("a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", "worker-3", 101),
+	}))
+
+	fetched := srv.RequestCount(http.MethodGet, "/nodes/pve-2/qemu/101/config")
+
+	vm, err := client.GetVMBySerialName(ctx, "worker-3")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(101), vm.VMID)
+	assert.Equal(t, "worker-3", vm.Name)
+
+	_, err = client.GetVMBySerialName(ctx, "worker-2")
+	require.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+
+	assert.Equal(t, 1, srv.RequestCount(http.MethodGet, "/nodes/pve-1/qemu/100/config"))
+	assert.Equal(t, fetched+1, srv.RequestCount(http.MethodGet, "/nodes/pve-2/qemu/101/config"))
+}
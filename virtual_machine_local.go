@@ -28,6 +28,7 @@ import (
 	yaml "go.yaml.in/yaml/v3"
 )
 
+// GetLocalVMConfigByFilter returns the first local VM configuration matching all the provided filter functions.
 func GetLocalVMConfigByFilter(filter ...func(*proxmox.VirtualMachineConfig) (bool, error)) (int, *proxmox.VirtualMachineConfig, error) {
 	entries, err := os.ReadDir("/etc/pve/qemu-server/")
 	if err != nil {
@@ -50,19 +51,13 @@ func GetLocalVMConfigByFilter(filter ...func(*proxmox.VirtualMachineConfig) (boo
 				continue // Skip VMs that can't be read
 			}
 
-			if len(filter) == 0 {
-				return vmID, vm, nil
+			match, err := And(filter...)(vm)
+			if err != nil {
+				return 0, nil, fmt.Errorf("filter function error for VM %d: %w", vmID, err)
 			}
 
-			for _, filterFunc := range filter {
-				match, err := filterFunc(vm)
-				if err != nil {
-					return 0, nil, fmt.Errorf("filter function error for VM %d: %w", vmID, err)
-				}
-
-				if match {
-					return vmID, vm, nil
-				}
+			if match {
+				return vmID, vm, nil
 			}
 		}
 	}
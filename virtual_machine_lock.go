@@ -0,0 +1,159 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxLockRetryInterval is the maximum delay between the attempts to run an operation on a locked VM.
+const maxLockRetryInterval = 10 * time.Second
+
+// VMLock is the lock of the VM configuration, set while an operation owns the VM.
+type VMLock string
+
+const (
+	// VMLockNone means the VM is not locked.
+	VMLockNone VMLock = ""
+	// VMLockBackup is set while the VM is backed up.
+	VMLockBackup VMLock = "backup"
+	// VMLockClone is set on the VM and its template while the VM is cloned.
+	VMLockClone VMLock = "clone"
+	// VMLockCreate is set while the VM is created or restored.
+	VMLockCreate VMLock = "create"
+	// VMLockMigrate is set while the VM is migrated.
+	VMLockMigrate VMLock = "migrate"
+	// VMLockRollback is set while the VM is rolled back to a snapshot.
+	VMLockRollback VMLock = "rollback"
+	// VMLockSnapshot is set while a snapshot of the VM is taken.
+	VMLockSnapshot VMLock = "snapshot"
+	// VMLockSnapshotDelete is set while a snapshot of the VM is deleted.
+	VMLockSnapshotDelete VMLock = "snapshot-delete"
+	// VMLockSuspending is set while the VM is suspended to disk.
+	VMLockSuspending VMLock = "suspending"
+	// VMLockSuspended is set on a VM suspended to disk, until it is resumed.
+	VMLockSuspended VMLock = "suspended"
+)
+
+// GetVMLock returns the lock of the VM configuration, or VMLockNone if the VM is not locked.
+func (c *APIClient) GetVMLock(ctx context.Context, nodeName string, vmID int) (VMLock, error) {
+	config := proxmox.VirtualMachineConfig{}
+	if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", nodeName, vmID), &config); err != nil {
+		return VMLockNone, fmt.Errorf("unable to get config of vm %d: %w", vmID, err)
+	}
+
+	return VMLock(config.Lock), nil
+}
+
+// UnlockVM removes a stale lock from the VM configuration, like qm unlock.
+// The API allows it only for root@pam. A lock of a running operation must not be removed.
+func (c *APIClient) UnlockVM(ctx context.Context, nodeName string, vmID int) (err error) {
+	ctx, span := c.startSpan(ctx, "UnlockVM", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	params := map[string]any{
+		"delete":   "lock",
+		"skiplock": 1,
+	}
+
+	if err := c.Client.Put(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", nodeName, vmID), params, nil); err != nil {
+		return fmt.Errorf("unable to unlock vm %d: %w", vmID, err)
+	}
+
+	return nil
+}
+
+// lockRetry is the backoff state of an operation retried while the VM is locked.
+type lockRetry struct {
+	deadline time.Time
+	interval time.Duration
+	attempt  int
+}
+
+// newLockRetry starts the lock wait of an operation. Without WithLockRetry the operation is not retried.
+func (c *APIClient) newLockRetry() *lockRetry {
+	if c.lockWait <= 0 {
+		return nil
+	}
+
+	return &lockRetry{
+		deadline: time.Now().Add(c.lockWait),
+		interval: c.lockRetryInterval,
+	}
+}
+
+// backoff waits before the next attempt. It returns false if the deadline would pass or the context is done.
+func (r *lockRetry) backoff(ctx context.Context, cause error) bool {
+	if r == nil || !errorIs(cause, ErrLocked) {
+		return false
+	}
+
+	delay := min(r.interval<<min(r.attempt, 16), max(r.interval, maxLockRetryInterval))
+	if time.Until(r.deadline) < delay {
+		return false
+	}
+
+	r.attempt++
+
+	trace.SpanFromContext(ctx).AddEvent("proxmox.lock.retry", trace.WithAttributes(
+		attribute.Int("proxmox.lock.attempt", r.attempt),
+		attribute.String("proxmox.lock.error", cause.Error()),
+	))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// retryLocked starts a task with fn and retries it while it fails because the VM is locked.
+func (c *APIClient) retryLocked(ctx context.Context, r *lockRetry, fn func(context.Context) (*proxmox.Task, error)) (*proxmox.Task, error) {
+	for {
+		task, err := fn(ctx)
+		if err == nil || !r.backoff(ctx, err) {
+			return task, err
+		}
+	}
+}
+
+// runLocked starts a task with fn and waits for it. Both are retried while the VM is locked.
+func (c *APIClient) runLocked(ctx context.Context, op Operation, fn func(context.Context) (*proxmox.Task, error)) error {
+	r := c.newLockRetry()
+
+	for {
+		task, err := c.retryLocked(ctx, r, fn)
+		if err != nil || task == nil {
+			return err
+		}
+
+		err = c.waitTask(ctx, task, op)
+		if err == nil || !r.backoff(ctx, err) {
+			return err
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestUnlockVM(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "lock": "backup"})
+
+	lock, err := client.GetVMLock(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, goproxmox.VMLockBackup, lock)
+
+	require.NoError(t, client.UnlockVM(ctx, "pve-1", 100))
+
+	lock, err = client.GetVMLock(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, goproxmox.VMLockNone, lock)
+
+	require.NoError(t, client.UpdateVMByID(ctx, "pve-1", 100, map[string]any{"description": "test"}))
+}
+
+func TestLockRetry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("locked", func(t *testing.T) {
+		t.Parallel()
+
+		srv, client := newTestCluster(t, goproxmox.WithLockRetry(5*time.Second, 10*time.Millisecond))
+		srv.TaskDuration = 300 * time.Millisecond
+
+		h, err := client.CloneVMAsync(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 101, Name: "worker", Full: 1})
+		require.NoError(t, err)
+
+		lock, err := client.GetVMLock(ctx, "pve-1", 101)
+		require.NoError(t, err)
+		assert.Equal(t, goproxmox.VMLockClone, lock)
+
+		require.NoError(t, client.UpdateVMByID(ctx, "pve-1", 101, map[string]any{"description": "test"}))
+		require.NoError(t, h.Wait(ctx))
+
+		vm, ok := srv.VM(101)
+		require.True(t, ok)
+		assert.Equal(t, "test", vm.Config["description"])
+	})
+
+	t.Run("task-failed", func(t *testing.T) {
+		t.Parallel()
+
+		srv, client := newTestCluster(t, goproxmox.WithLockRetry(5*time.Second, 10*time.Millisecond))
+		srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+		srv.FailTask("qmconfig", "can't lock file '/var/lock/qemu-server/lock-100.conf' - got timeout")
+
+		require.NoError(t, client.UpdateVMByID(ctx, "pve-1", 100, map[string]any{"description": "test"}))
+
+		tasks, err := client.ListTasks(ctx, goproxmox.TaskFilter{Node: "pve-1", Type: "qmconfig"})
+		require.NoError(t, err)
+		require.Len(t, tasks, 2)
+		assert.False(t, tasks[0].IsFailed())
+		assert.True(t, tasks[1].IsFailed())
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		t.Parallel()
+
+		srv, client := newTestCluster(t, goproxmox.WithLockRetry(100*time.Millisecond, 10*time.Millisecond))
+		srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "lock": "backup"})
+
+		err := client.UpdateVMByID(ctx, "pve-1", 100, map[string]any{"description": "test"})
+		require.ErrorIs(t, err, goproxmox.ErrLocked)
+	})
+}
@@ -18,16 +18,37 @@ package goproxmox
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/avast/retry-go/v4"
 	"github.com/luthermonson/go-proxmox"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // StartVMByID starts a VM by its ID.
-func (c *APIClient) StartVMByID(ctx context.Context, nodeName string, vmID int) (*proxmox.VirtualMachine, error) {
+func (c *APIClient) StartVMByID(ctx context.Context, nodeName string, vmID int) (_ *proxmox.VirtualMachine, err error) {
+	ctx, span := c.startSpan(ctx, "StartVMByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.StartVMByIDAsync(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	if err = h.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.getVM(ctx, nodeName, vmID)
+}
+
+// StartVMByIDAsync starts a VM by its ID and returns the handle of the start task.
+func (c *APIClient) StartVMByIDAsync(ctx context.Context, nodeName string, vmID int) (_ *TaskHandle, err error) {
 	vm := &proxmox.VirtualMachine{}
 	vm.New(c.Client, nodeName, vmID)
 
@@ -35,30 +56,23 @@ func (c *APIClient) StartVMByID(ctx context.Context, nodeName string, vmID int)
 		return nil, fmt.Errorf("unable to find vm with id %d: %w", vmID, err)
 	}
 
-	defer func() {
-		c.flushResources("vm")
-	}()
-
-	task, err := vm.Start(ctx)
+	release, err := c.limiter.acquire(ctx, []string{nodeName}, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to start vm %d: %v", vmID, err)
+		return nil, err
 	}
 
-	if task != nil {
-		if err = task.WaitFor(ctx, 60); err != nil {
-			return nil, fmt.Errorf("unable to start virtual machine: %w", err)
-		}
-
-		if task.IsFailed {
-			return nil, fmt.Errorf("unable to start virtual machine: %s", task.ExitStatus)
+	h := c.newTaskHandle(OperationStart, vmID, "unable to start virtual machine", release)
+	defer func() {
+		if err != nil {
+			h.finish()
 		}
-	}
+	}()
 
-	if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", nodeName, vmID), &vm.VirtualMachineConfig); err != nil {
-		return nil, err
+	if err = h.startTask(ctx, vm.Start); err != nil {
+		return nil, fmt.Errorf("failed to start vm %d: %w", vmID, err)
 	}
 
-	return vm, nil
+	return h, nil
 }
 
 // DeleteVMByID deletes a VM by its ID.
@@ -69,96 +83,223 @@ func (c *APIClient) StartVMByID(ctx context.Context, nodeName string, vmID int)
 // races against the still-running stop and fails with
 // "can't lock file '/var/lock/qemu-server/lock-<vmid>.conf' - got timeout",
 // leaving an orphan VM behind.
-func (c *APIClient) DeleteVMByID(ctx context.Context, nodeName string, vmID int) error {
+//
+// Use WithGracefulShutdown to shut down the guest instead, so it can flush its filesystems.
+func (c *APIClient) DeleteVMByID(ctx context.Context, nodeName string, vmID int, options ...DeleteVMOption) (err error) {
+	ctx, span := c.startSpan(ctx, "DeleteVMByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.DeleteVMByIDAsync(ctx, nodeName, vmID, options...)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// DeleteVMByIDAsync deletes a VM by its ID and returns the task handle.
+// If the VM is running, the handle tracks the stop or the shutdown task and Wait deletes the VM after it.
+// Other running tasks of the VM are handled according to WithTaskConflictPolicy.
+func (c *APIClient) DeleteVMByIDAsync(ctx context.Context, nodeName string, vmID int, options ...DeleteVMOption) (_ *TaskHandle, err error) {
+	opts := deleteVMOptions{}
+	for _, o := range options {
+		o(&opts)
+	}
+
+	if err := c.checkTaskConflicts(ctx, nodeName, vmID, OperationDelete); err != nil {
+		return nil, err
+	}
+
 	vm := &proxmox.VirtualMachine{}
 	vm.New(c.Client, nodeName, vmID)
 
 	if err := vm.Ping(ctx); err != nil {
-		return fmt.Errorf("unable to find vm with id %d: %w", vmID, err)
+		return nil, fmt.Errorf("unable to find vm with id %d: %w", vmID, err)
 	}
 
-	if vm.IsRunning() {
-		task, err := vm.Stop(ctx)
+	release, err := c.limiter.acquire(ctx, []string{nodeName}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationDelete, vmID, fmt.Sprintf("unable to delete vm %d", vmID), release)
+	defer func() {
 		if err != nil {
-			return fmt.Errorf("failed to stop vm %d: %v", vmID, err)
+			h.finish()
 		}
+	}()
 
-		if task != nil {
-			if err = task.WaitFor(ctx, 60); err != nil {
-				return fmt.Errorf("unable to stop vm %d: %w", vmID, err)
+	if vm.IsRunning() {
+		if opts.shutdown != nil {
+			h.op = OperationShutdown
+			h.desc = fmt.Sprintf("unable to shut down vm %d", vmID)
+
+			if err = c.startShutdown(ctx, h, vm, *opts.shutdown); err != nil {
+				return nil, err
 			}
+		} else {
+			h.op = OperationStop
+			h.desc = fmt.Sprintf("unable to stop vm %d", vmID)
 
-			if task.IsFailed {
-				return fmt.Errorf("unable to stop vm %d: %s", vmID, task.ExitStatus)
+			if err = h.startTask(ctx, vm.Stop); err != nil {
+				return nil, fmt.Errorf("failed to stop vm %d: %w", vmID, err)
 			}
 		}
+
+		h.then = func(ctx context.Context) error {
+			if err := c.runLocked(ctx, OperationDelete, func(ctx context.Context) (*proxmox.Task, error) {
+				return c.deleteVM(ctx, vm)
+			}); err != nil {
+				return fmt.Errorf("unable to delete vm %d: %w", vmID, err)
+			}
+
+			c.lastVMID.Set(strconv.Itoa(vmID), struct{}{}, c.vmidTTL)
+
+			return nil
+		}
+
+		return h, nil
 	}
 
-	defer func() {
-		c.flushResources("vm")
-	}()
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		return c.deleteVM(ctx, vm)
+	}); err != nil {
+		return nil, err
+	}
 
-	task, err := vm.Delete(ctx)
-	if err != nil {
-		return fmt.Errorf("cannot delete vm with id %d: %w", vmID, err)
+	h.then = func(context.Context) error {
+		c.lastVMID.Set(strconv.Itoa(vmID), struct{}{}, c.vmidTTL)
+
+		return nil
 	}
 
-	if task != nil {
-		if err = task.WaitFor(ctx, 60); err != nil {
-			return fmt.Errorf("unable to delete vm %d: %w", vmID, err)
-		}
+	return h, nil
+}
 
-		if task.IsFailed {
-			return fmt.Errorf("unable to delete vm %d: %s", vmID, task.ExitStatus)
-		}
+// DeleteVMOption configures DeleteVMByID.
+type DeleteVMOption func(*deleteVMOptions)
+
+type deleteVMOptions struct {
+	shutdown *ShutdownOptions
+}
+
+// WithGracefulShutdown shuts down the guest of a running VM before it is deleted, instead of stopping it.
+// Without ForceStop the VM is not deleted if the guest does not power off within the timeout.
+func WithGracefulShutdown(opts ShutdownOptions) DeleteVMOption {
+	return func(o *deleteVMOptions) {
+		o.shutdown = &opts
 	}
+}
 
-	c.lastVMID.SetDefault(strconv.Itoa(vmID), struct{}{})
+func (c *APIClient) deleteVM(ctx context.Context, vm *proxmox.VirtualMachine) (*proxmox.Task, error) {
+	task, err := vm.Delete(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot delete vm with id %d: %w", vm.VMID, err)
+	}
 
-	return nil
+	return task, nil
 }
 
 // MigrateVMByID migrates a VM to another node by its ID.
-func (c *APIClient) MigrateVMByID(ctx context.Context, vmID int, dstNode string, online bool) error {
-	vmr, err := c.GetVMByID(ctx, uint64(vmID))
+// The migration is checked with CheckVMMigration first and fails with ErrMigrationBlocked
+// if the VM can't migrate to the node with the options.
+func (c *APIClient) MigrateVMByID(ctx context.Context, vmID int, dstNode string, online bool, options ...MigrateVMOption) (err error) {
+	ctx, span := c.startSpan(ctx, "MigrateVMByID", AttrVMID.Int(vmID), attribute.String("proxmox.target_node", dstNode))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.MigrateVMByIDAsync(ctx, vmID, dstNode, online, options...)
 	if err != nil {
 		return err
 	}
+	defer h.Close()
 
-	defer func() {
-		c.flushResources("vm")
-	}()
+	return h.Wait(ctx)
+}
 
-	params := &proxmox.VirtualMachineMigrateOptions{
-		Target: dstNode,
-		Online: proxmox.IntOrBool(online),
+// MigrateVMByIDAsync migrates a VM to another node by its ID and returns the handle of the migration task.
+func (c *APIClient) MigrateVMByIDAsync(ctx context.Context, vmID int, dstNode string, online bool, options ...MigrateVMOption) (_ *TaskHandle, err error) {
+	opts := migrateVMOptions{online: online}
+	for _, o := range options {
+		o(&opts)
 	}
 
-	var upid proxmox.UPID
-	if err = c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/migrate", vmr.Node, vmr.VMID), params, &upid); err != nil {
-		return err
+	vmr, err := c.GetVMByID(ctx, uint64(vmID))
+	if err != nil {
+		return nil, err
 	}
 
-	task := proxmox.NewTask(upid, c.Client)
-	if task != nil {
-		if err = task.WaitFor(ctx, 5*60); err != nil {
-			return fmt.Errorf("unable to migrate virtual machine: %w", err)
+	check, err := c.checkVMMigration(ctx, vmr.Node, vmID, dstNode, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(check.Blockers) > 0 {
+		return nil, fmt.Errorf("unable to migrate vm %d to node %s: %w: %s", vmID, dstNode, ErrMigrationBlocked, strings.Join(check.Blockers, "; "))
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{vmr.Node, dstNode}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationMigrate, vmID, "unable to migrate virtual machine", release)
+	defer func() {
+		if err != nil {
+			h.finish()
 		}
+	}()
+
+	params := opts.params(dstNode)
 
-		if task.IsFailed {
-			return fmt.Errorf("unable to migrate virtual machine: %s", task.ExitStatus)
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/migrate", vmr.Node, vmr.VMID), params, &upid); err != nil {
+			return nil, err
 		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return h, nil
 }
 
 // CreateVM creates a new VM on the specified node with the given configuration.
-func (c *APIClient) CreateVM(ctx context.Context, node string, options map[string]interface{}) error {
+func (c *APIClient) CreateVM(ctx context.Context, node string, options map[string]interface{}) (err error) {
+	ctx, span := c.startSpan(ctx, "CreateVM", AttrNode.String(node))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.CreateVMAsync(ctx, node, options)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// CreateVMAsync creates a new VM on the specified node and returns the handle of the create task.
+// If the options contain template=1, Wait converts the VM to a template.
+func (c *APIClient) CreateVMAsync(ctx context.Context, node string, options map[string]interface{}) (_ *TaskHandle, err error) {
 	var upid proxmox.UPID
 
+	vmID, err := getOptionsVMID(options)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create virtual machine: %w", err)
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{node}, c.storageSlots(ctx, node, getOptionsStorages(options)))
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationCreate, vmID, "unable to create virtual machine", release)
 	defer func() {
-		c.flushResources("vm")
+		if err != nil {
+			h.finish()
+		}
 	}()
 
 	template := options["template"] == 1
@@ -167,96 +308,191 @@ func (c *APIClient) CreateVM(ctx context.Context, node string, options map[strin
 	}
 
 	if err := c.Post(ctx, fmt.Sprintf("/nodes/%s/qemu", node), &options, &upid); nil != err {
-		return fmt.Errorf("unable to create virtual machine: %w", err)
-	}
-
-	task := proxmox.NewTask(upid, c.Client)
-	if err := task.WaitFor(ctx, 5*60); err != nil {
-		return fmt.Errorf("unable to create virtual machine: %w", err)
-	}
-
-	if task.IsFailed {
-		return fmt.Errorf("unable to create virtual machine: %s", task.ExitStatus)
+		return nil, fmt.Errorf("unable to create virtual machine: %w", err)
 	}
 
-	if template {
-		if err := c.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/template", node, options["vmid"]), nil, &upid); nil != err {
-			return fmt.Errorf("unable to create template of virtual machine: %w", err)
+	h.setTask(proxmox.NewTask(upid, c.Client))
+	h.then = func(ctx context.Context) error {
+		if template {
+			return c.convertToTemplate(ctx, node, vmID)
 		}
 
-		task := proxmox.NewTask(upid, c.Client)
-		if err := task.WaitFor(ctx, 60); err != nil {
-			return fmt.Errorf("unable to convert to template of virtual machine: %w", err)
+		if err := c.waitVMStatus(ctx, uint64(vmID)); err != nil {
+			return fmt.Errorf("unable to verify of virtual machine: %w", err)
 		}
 
-		if task.IsFailed {
-			return fmt.Errorf("unable to convert to template of virtual machine: %s", task.ExitStatus)
-		}
+		return nil
+	}
 
-		if err := retry.Do(func() error {
-			c.flushResources("vm")
-			_, err := c.GetVMTemplateByID(ctx, uint64(options["vmid"].(int)))
+	return h, nil
+}
 
-			return err
-		}, retry.Attempts(6), retry.Delay(2*time.Second)); err != nil {
-			return fmt.Errorf("unable to verify template of virtual machine: %w", err)
-		}
+func (c *APIClient) convertToTemplate(ctx context.Context, node string, vmID int) error {
+	var upid proxmox.UPID
 
-		return nil
+	if err := c.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/template", node, vmID), nil, &upid); nil != err {
+		return fmt.Errorf("unable to create template of virtual machine: %w", err)
 	}
 
-	if err := c.waitVMStatus(ctx, uint64(options["vmid"].(int))); err != nil {
-		return fmt.Errorf("unable to verify of virtual machine: %w", err)
+	task := proxmox.NewTask(upid, c.Client)
+	if err := c.waitTask(ctx, task, OperationTemplate); err != nil {
+		return fmt.Errorf("unable to convert to template of virtual machine: %w", err)
+	}
+
+	if err := retry.Do(func() error {
+		c.flushResources("vm")
+		_, err := c.GetVMTemplateByID(ctx, uint64(vmID))
+
+		return err
+	}, retry.Attempts(6), retry.Delay(2*time.Second)); err != nil {
+		return fmt.Errorf("unable to verify template of virtual machine: %w", err)
 	}
 
 	return nil
 }
 
 // UpdateVMByID updates an existing VM on the specified node with the given configuration.
-func (c *APIClient) UpdateVMByID(ctx context.Context, nodeName string, vmID int, options map[string]interface{}) error {
+func (c *APIClient) UpdateVMByID(ctx context.Context, nodeName string, vmID int, options map[string]interface{}) (err error) {
+	ctx, span := c.startSpan(ctx, "UpdateVMByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.UpdateVMByIDAsync(ctx, nodeName, vmID, options)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// UpdateVMByIDAsync updates an existing VM with the given configuration and returns the handle of the config task.
+// The handle has no task if the configuration is already up to date.
+// The running tasks of the VM are handled according to WithTaskConflictPolicy.
+func (c *APIClient) UpdateVMByIDAsync(ctx context.Context, nodeName string, vmID int, options map[string]interface{}) (_ *TaskHandle, err error) {
+	if err := c.checkTaskConflicts(ctx, nodeName, vmID, OperationConfig); err != nil {
+		return nil, err
+	}
+
 	vm := &proxmox.VirtualMachine{}
 	vm.New(c.Client, nodeName, vmID)
 
 	if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", nodeName, vmID), &vm.VirtualMachineConfig); err != nil {
-		return err
+		return nil, err
 	}
 
 	vmOptions := getVMOptionsToApply(vm.VirtualMachineConfig, options)
 	if len(vmOptions) == 0 {
-		return nil
+		h := c.newTaskHandle(OperationConfig, vmID, "unable to configure virtual machine", nil)
+		h.flush = false
+
+		return h, nil
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{nodeName}, nil)
+	if err != nil {
+		return nil, err
 	}
 
+	h := c.newTaskHandle(OperationConfig, vmID, "unable to configure virtual machine", release)
 	defer func() {
-		c.flushResources("vm")
+		if err != nil {
+			h.finish()
+		}
 	}()
 
-	task, err := vm.Config(ctx, vmOptions...)
-	if err != nil {
-		return fmt.Errorf("unable to configure vm: %w", err)
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		return vm.Config(ctx, vmOptions...)
+	}); err != nil {
+		return nil, fmt.Errorf("unable to configure vm: %w", err)
 	}
 
-	if task != nil {
-		if err = task.WaitFor(ctx, 5*60); err != nil {
-			return fmt.Errorf("unable to configure virtual machine: %w", err)
-		}
+	return h, nil
+}
 
-		if task.IsFailed {
-			return fmt.Errorf("unable to configure virtual machine: %s", task.ExitStatus)
+// CloneVM clones a VM template to create a new VM with the specified options.
+func (c *APIClient) CloneVM(ctx context.Context, templateID int, options VMCloneRequest) (_ int, err error) {
+	ctx, span := c.startSpan(ctx, "CloneVM", AttrNode.String(options.Node), attribute.Int("proxmox.template_vmid", templateID), AttrStorage.String(options.Storage),
+		attribute.String("proxmox.target_node", options.TargetNode))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.CloneVMAsync(ctx, templateID, options)
+	if err != nil {
+		var cloneErr *CloneError
+		if errors.As(err, &cloneErr) {
+			return cloneErr.VMID, err
 		}
+
+		return 0, err
 	}
+	defer h.Close()
 
-	return nil
+	span.SetAttributes(AttrVMID.Int(h.VMID()))
+
+	return h.VMID(), h.Wait(ctx)
 }
 
-// CloneVM clones a VM template to create a new VM with the specified options.
-func (c *APIClient) CloneVM(ctx context.Context, templateID int, options VMCloneRequest) (int, error) {
+// CloneVMAsync clones a VM template and returns the handle of the clone task.
+// Wait applies the instance options of the request to the new VM.
+//
+// A template on node local storages is cloned to another target node from its replica on the
+// target node, see WithTemplateReplicas. Without a replica the template is fully cloned on its node
+// and Wait migrates the new VM to the target node.
+//
+// If the clone request fails after the VM ID was allocated, the error is a *CloneError
+// carrying the ID so the caller can clean up.
+func (c *APIClient) CloneVMAsync(ctx context.Context, templateID int, options VMCloneRequest) (_ *TaskHandle, err error) {
+	plan, err := c.planClone(ctx, templateID, options)
+	if err != nil {
+		return nil, err
+	}
+
 	vmTemplate := &proxmox.VirtualMachine{}
-	vmTemplate.New(c.Client, options.Node, templateID)
+	vmTemplate.New(c.Client, plan.node, plan.templateID)
 
 	if err := vmTemplate.Ping(ctx); err != nil {
-		return 0, fmt.Errorf("unable to find vm with id %d: %w", templateID, err)
+		return nil, fmt.Errorf("unable to find vm with id %d: %w", plan.templateID, err)
+	}
+
+	// A full clone migrated to the target node afterwards is created on the template storages.
+	storages := []string{options.Storage}
+	if (options.Storage == "" || plan.migrate) && c.limiter.storageLimit > 0 {
+		if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", plan.node, plan.templateID), &vmTemplate.VirtualMachineConfig); err != nil {
+			return nil, fmt.Errorf("failed to get config of vm template %d: %w", plan.templateID, err)
+		}
+
+		storages = getVMStorages(vmTemplate.VirtualMachineConfig)
+	}
+
+	// The clone disks are created on the target node, unless the new VM is migrated there afterwards.
+	diskNode := plan.targetNode
+	if plan.migrate {
+		diskNode = plan.node
+	}
+
+	slots := c.storageSlots(ctx, diskNode, storages)
+
+	// The migration by Wait moves the disks to the target storage, or to the same storages on the target node.
+	if plan.migrate && c.limiter.storageLimit > 0 {
+		targets := storages
+		if options.Storage != "" {
+			targets = []string{options.Storage}
+		}
+
+		slots = append(slots, c.storageSlots(ctx, plan.targetNode, targets)...)
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{plan.node, plan.targetNode}, slots)
+	if err != nil {
+		return nil, err
 	}
 
+	h := c.newTaskHandle(OperationClone, options.NewID, "unable to clone virtual machine", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
 	vmCloneOptions := proxmox.VirtualMachineCloneOptions{
 		NewID:       options.NewID,
 		Description: options.Description,
@@ -266,41 +502,65 @@ func (c *APIClient) CloneVM(ctx context.Context, templateID int, options VMClone
 		Storage:     options.Storage,
 	}
 
-	defer func() {
-		c.flushResources("vm")
-	}()
+	if plan.migrate {
+		vmCloneOptions.Full = 1
+		vmCloneOptions.Storage = ""
+	} else if plan.targetNode != plan.node {
+		vmCloneOptions.Target = plan.targetNode
+	}
 
 	newid, task, err := vmTemplate.Clone(ctx, &vmCloneOptions)
 	if err != nil {
-		return newid, fmt.Errorf("failed to clone vm template %d: %v", templateID, err)
-	}
+		err = fmt.Errorf("failed to clone vm template %d: %w", plan.templateID, err)
+		if newid != 0 {
+			err = &CloneError{VMID: newid, Err: err}
+		}
 
-	if err := task.WaitFor(ctx, 5*60); err != nil {
-		return newid, fmt.Errorf("unable to clone virtual machine: %w", err)
+		return nil, err
 	}
 
-	if task.IsFailed {
-		return newid, fmt.Errorf("unable to clone virtual machine: %s", task.ExitStatus)
+	options.Node = plan.targetNode
+
+	h.vmid = newid
+	h.setTask(task)
+	h.then = func(ctx context.Context) error {
+		if plan.migrate {
+			if err := c.migrateClonedVM(ctx, plan.node, newid, plan.targetNode, options.Storage); err != nil {
+				return err
+			}
+		}
+
+		return c.configureClonedVM(ctx, options, newid)
 	}
 
+	return h, nil
+}
+
+// configureClonedVM applies the instance options of the clone request to the new VM.
+func (c *APIClient) configureClonedVM(ctx context.Context, options VMCloneRequest, newid int) error {
 	vm := &proxmox.VirtualMachine{}
 	vm.New(c.Client, options.Node, newid)
 
 	if err := vm.Ping(ctx); err != nil {
-		return newid, fmt.Errorf("failed to get status of vm %d: %v", newid, err)
+		return fmt.Errorf("failed to get status of vm %d: %w", newid, err)
 	}
 
 	if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", vm.Node, vm.VMID), &vm.VirtualMachineConfig); err != nil {
-		return newid, fmt.Errorf("failed to get config of vm %d: %v", newid, err)
+		return fmt.Errorf("failed to get config of vm %d: %w", newid, err)
 	}
 
 	if options.DiskSize != "" {
 		bootDisk := detectBootDisk(vm.VirtualMachineConfig)
 		if bootDisk == "" {
-			return newid, fmt.Errorf("failed to detect boot disk for vm %d", newid)
+			return fmt.Errorf("failed to detect boot disk for vm %d", newid)
 		}
-		if _, err = vm.ResizeDisk(ctx, bootDisk, options.DiskSize); err != nil {
-			return newid, fmt.Errorf("failed to resize disk %s for vm %d: %v", bootDisk, newid, err)
+
+		resizeCtx, resizeSpan := c.startSpan(ctx, "vm.resizeDisk", AttrVMID.Int(newid), attribute.String("proxmox.disk", bootDisk))
+		_, err := vm.ResizeDisk(resizeCtx, bootDisk, options.DiskSize)
+		endSpan(resizeSpan, err)
+
+		if err != nil {
+			return fmt.Errorf("failed to resize disk %s for vm %d: %w", bootDisk, newid, err)
 		}
 	}
 
@@ -313,29 +573,28 @@ func (c *APIClient) CloneVM(ctx context.Context, templateID int, options VMClone
 	if len(vmOptions) > 0 {
 		task, err := vm.Config(ctx, vmOptions...)
 		if err != nil {
-			return newid, fmt.Errorf("unable to configure vm: %w", err)
+			return fmt.Errorf("unable to configure vm: %w", err)
 		}
 
 		if task != nil {
-			if err = task.WaitFor(ctx, 5*60); err != nil {
-				return newid, fmt.Errorf("unable to configure virtual machine: %w", err)
-			}
-
-			if task.IsFailed {
-				return newid, fmt.Errorf("unable to configure virtual machine: %s", task.ExitStatus)
+			if err = c.waitTask(ctx, task, OperationConfig); err != nil {
+				return fmt.Errorf("unable to configure virtual machine: %w", err)
 			}
 		}
 	}
 
 	if err := c.waitVMStatus(ctx, uint64(newid)); err != nil {
-		return newid, fmt.Errorf("unable to verify cloned virtual machine: %w", err)
+		return fmt.Errorf("unable to verify cloned virtual machine: %w", err)
 	}
 
-	return newid, nil
+	return nil
 }
 
 // RegenerateVMCloudInit regenerates the Cloud-Init configuration for a VM.
-func (c *APIClient) RegenerateVMCloudInit(ctx context.Context, node string, vmID int) error {
+func (c *APIClient) RegenerateVMCloudInit(ctx context.Context, node string, vmID int) (err error) {
+	ctx, span := c.startSpan(ctx, "RegenerateVMCloudInit", AttrNode.String(node), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
 	if err := c.Put(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/cloudinit", node, vmID), map[string]string{
 		"node": node,
 		"vmid": fmt.Sprintf("%d", vmID),
@@ -347,7 +606,10 @@ func (c *APIClient) RegenerateVMCloudInit(ctx context.Context, node string, vmID
 }
 
 // After creating, VM can have unknown status for a short time. Wait until we can get its info.
-func (c *APIClient) waitVMStatus(ctx context.Context, vmID uint64) error {
+func (c *APIClient) waitVMStatus(ctx context.Context, vmID uint64) (err error) {
+	ctx, span := c.startSpan(ctx, "vm.waitStatus", AttrVMID.Int64(int64(vmID)))
+	defer func() { endSpan(span, err) }()
+
 	if err := retry.Do(func() error {
 		c.flushResources("vm")
 
@@ -0,0 +1,262 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+	"github.com/sergelogvinov/go-proxmox/goproxmoxtest"
+)
+
+func newTestCluster(t *testing.T, options ...goproxmox.ClientOption) (*goproxmoxtest.Server, *goproxmox.APIClient) {
+	t.Helper()
+
+	srv := goproxmoxtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	for _, node := range []string{"pve-1", "pve-2"} {
+		srv.AddNode(node)
+		srv.AddStorage(node, "local-lvm", false)
+		srv.AddStorage(node, "rbd", true)
+	}
+
+	srv.AddTemplate("pve-1", 9000, map[string]any{
+		"name":    "template",
+		"cores":   1,
+		"memory":  "1024",
+		"scsi0":   "local-lvm:base-9000-disk-0,size=2G",
+		"net0":    "virtio=BC:24:11:00:00:01,bridge=vmbr0",
+		"smbios1": "uuid=00000000-0000-0000-0000-000000009000",
+	})
+
+	options = append([]goproxmox.ClientOption{goproxmox.WithTaskPollInterval(10 * time.Millisecond)}, options...)
+
+	client, err := goproxmox.NewAPIClientWithOptions(srv.URL(), options...)
+	require.NoError(t, err)
+
+	return srv, client
+}
+
+func TestCloneVM(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	id, err := client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{
+		Node:         "pve-1",
+		NewID:        100,
+		Name:         "worker-1",
+		Full:         1,
+		CPU:          4,
+		Memory:       4096,
+		DiskSize:     "10G",
+		InstanceType: "4VCPU-4GB",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 100, id)
+
+	vm, err := client.GetVMConfig(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "worker-1", vm.VirtualMachineConfig.Name)
+	assert.Equal(t, 4, vm.VirtualMachineConfig.Cores)
+	assert.Equal(t, "local-lvm:vm-100-disk-0,size=10G", vm.VirtualMachineConfig.SCSI0)
+	assert.Equal(t, "4VCPU-4GB", goproxmox.GetVMSKU(vm))
+	assert.NotEqual(t, "00000000-0000-0000-0000-000000009000", goproxmox.GetVMUUID(vm))
+
+	state, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "pve-1", state.Node)
+}
+
+func TestCloneVM_Failed(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.FailTask("qmclone", "clone failed: out of space")
+
+	_, err := client.CloneVM(context.Background(), 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-1"})
+	assert.ErrorContains(t, err, "clone failed: out of space")
+
+	var taskErr *goproxmox.TaskFailedError
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, "qmclone", taskErr.Type)
+	assert.Equal(t, []string{"create linked clone of drive scsi0 (local-lvm:base-9000-disk-0)"}, taskErr.Log)
+}
+
+func TestCreateVM(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	err := client.CreateVM(ctx, "pve-2", map[string]interface{}{
+		"vmid":     9001,
+		"name":     "template-2",
+		"scsi0":    "rbd:4",
+		"template": 1,
+	})
+	require.NoError(t, err)
+
+	tmpl, err := client.GetVMTemplateByID(ctx, 9001)
+	require.NoError(t, err)
+	assert.Equal(t, "pve-2", tmpl.Node)
+	assert.Equal(t, []string{"rbd:base-9001-disk-0"}, srv.Volumes("pve-2", "rbd"))
+}
+
+func TestCreateVMAsync_VMID(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	for i, vmid := range []any{uint64(100), "101", json.Number("102"), float64(103)} {
+		h, err := client.CreateVMAsync(ctx, "pve-1", map[string]interface{}{"vmid": vmid, "name": "worker"})
+		require.NoError(t, err)
+		assert.Equal(t, 100+i, h.VMID())
+		require.NoError(t, h.Wait(ctx))
+
+		_, ok := srv.VM(100 + i)
+		assert.True(t, ok)
+	}
+
+	for _, vmid := range []any{"worker", float64(104.5), 99, true} {
+		_, err := client.CreateVMAsync(ctx, "pve-1", map[string]interface{}{"vmid": vmid, "name": "worker"})
+		require.ErrorIs(t, err, goproxmox.ErrBadRequest)
+	}
+}
+
+func TestDeleteVMByID(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	_, err := client.StartVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	require.NoError(t, client.DeleteVMByID(ctx, "pve-1", 100))
+
+	_, ok := srv.VM(100)
+	assert.False(t, ok)
+
+	_, err = client.GetVMByID(ctx, 100)
+	assert.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+}
+
+func TestMigrateVMByID(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "scsi0": "rbd:vm-100-disk-0,size=10G"})
+	srv.AddVM("pve-1", 101, map[string]any{"name": "worker-2", "scsi0": "local-lvm:vm-101-disk-0,size=10G"})
+	srv.AddVM("pve-1", 102, map[string]any{"name": "worker-3", "scsi0": "local-lvm:vm-102-disk-0,size=10G"})
+
+	require.NoError(t, client.MigrateVMByID(ctx, 100, "pve-2", false))
+
+	vmr, err := client.GetVMByID(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "pve-2", vmr.Node)
+
+	// The offline migration copies the local disks.
+	require.NoError(t, client.MigrateVMByID(ctx, 101, "pve-2", false))
+
+	vm, ok := srv.VM(101)
+	require.True(t, ok)
+	assert.Equal(t, "pve-2", vm.Node)
+	assert.Equal(t, "local-lvm:vm-101-disk-0,size=10G", vm.Config["scsi0"])
+
+	_, err = client.StartVMByID(ctx, "pve-1", 102)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, client.MigrateVMByID(ctx, 102, "pve-2", true), goproxmox.ErrMigrationBlocked)
+}
+
+func TestCloneVM_Timeout(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithOperationTimeout(goproxmox.OperationClone, 50*time.Millisecond))
+	srv.TaskDuration = time.Second
+
+	_, err := client.CloneVM(context.Background(), 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-1"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = client.CreateVMDisk(context.Background(), 101, "pve-1", "local-lvm", "vm-101-disk-0", 1<<30)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = client.DeleteVMDisk(ctx, "pve-1", "local-lvm", "vm-101-disk-0")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestCloneVM_Canceled(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t, goproxmox.WithTaskCancellation())
+	srv.TaskDuration = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err := client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-1"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var cancelErr *goproxmox.TaskCanceledError
+	require.ErrorAs(t, err, &cancelErr)
+	assert.Equal(t, goproxmox.TaskStopped, cancelErr.Result)
+
+	tasks := srv.Tasks()
+	require.NotEmpty(t, tasks)
+	assert.Equal(t, "stopped", tasks[len(tasks)-1].Status)
+	assert.Equal(t, "interrupted by signal", tasks[len(tasks)-1].ExitStatus)
+}
+
+func TestCloneVM_CanceledAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	// The task fails between two polls, before the context is done.
+	srv, client := newTestCluster(t, goproxmox.WithTaskCancellation(), goproxmox.WithTaskPollInterval(time.Second))
+	srv.TaskDuration = 50 * time.Millisecond
+	srv.FailTask("qmclone", "clone failed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: 100, Name: "worker-1"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var cancelErr *goproxmox.TaskCanceledError
+	require.ErrorAs(t, err, &cancelErr)
+	assert.Equal(t, goproxmox.TaskFinished, cancelErr.Result)
+
+	var taskErr *goproxmox.TaskFailedError
+	require.ErrorAs(t, err, &taskErr)
+	assert.Equal(t, "clone failed", taskErr.ExitStatus)
+}
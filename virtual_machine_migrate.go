@@ -0,0 +1,266 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/luthermonson/go-proxmox"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MigrationCheck is the report of the migration pre-flight check of a VM.
+type MigrationCheck struct {
+	// Running is true if the VM is running and has to be migrated online.
+	Running bool
+	// AllowedNodes are the nodes the VM can migrate to, Proxmox reports them only for a stopped VM.
+	AllowedNodes []string
+	// UnavailableStorages are the storages of the VM missing on the other nodes, by node.
+	// Proxmox reports them only for a stopped VM.
+	UnavailableStorages map[string][]string
+	// LocalDisks are the disks of the VM on node local storages, they are copied by the migration.
+	LocalDisks []MigrationLocalDisk
+	// LocalResources are the host devices of the VM, e.g. hostpci0 or usb0.
+	LocalResources []string
+	// MappedResources are the devices of the VM mapped on every node.
+	MappedResources []string
+	// Blockers are the reasons the VM can't migrate to the target node with the migration options.
+	Blockers []string
+}
+
+// MigrationLocalDisk is a VM disk on a node local storage.
+type MigrationLocalDisk struct {
+	VolID      string
+	Drive      string
+	Size       uint64
+	CDROM      bool
+	Unused     bool
+	Replicated bool
+}
+
+type migratePreconditions struct {
+	Running         proxmox.IntOrBool `json:"running"`
+	AllowedNodes    []string          `json:"allowed_nodes"`
+	NotAllowedNodes map[string]struct {
+		UnavailableStorages []string `json:"unavailable_storages"`
+	} `json:"not_allowed_nodes"`
+	LocalDisks []struct {
+		VolID      string            `json:"volid"`
+		DriveName  string            `json:"drivename"`
+		Size       uint64            `json:"size"`
+		CDROM      proxmox.IntOrBool `json:"cdrom"`
+		IsUnused   proxmox.IntOrBool `json:"is_unused"`
+		Replicated proxmox.IntOrBool `json:"replicated"`
+	} `json:"local_disks"`
+	LocalResources  []string `json:"local_resources"`
+	MappedResources []string `json:"mapped-resources"`
+}
+
+// MigrateVMOption configures MigrateVMByID and CheckVMMigration.
+type MigrateVMOption func(*migrateVMOptions)
+
+type migrateVMOptions struct {
+	online         bool
+	withLocalDisks bool
+	targetStorage  string
+	storageMap     map[string]string
+	bandwidthLimit int
+}
+
+// WithLocalDisks mirrors the disks on node local storages of a running VM to the target node.
+// Without it a running VM with local disks can't migrate online, an offline migration copies them anyway.
+func WithLocalDisks() MigrateVMOption {
+	return func(o *migrateVMOptions) {
+		o.withLocalDisks = true
+	}
+}
+
+// WithTargetStorage sets the storage on the target node for the local disks.
+// The default is the storage with the same name.
+func WithTargetStorage(storage string) MigrateVMOption {
+	return func(o *migrateVMOptions) {
+		o.targetStorage = storage
+	}
+}
+
+// WithStorageMap maps the storages of the local disks to the storages on the target node.
+// The storages missing from the map are migrated to WithTargetStorage.
+func WithStorageMap(storages map[string]string) MigrateVMOption {
+	return func(o *migrateVMOptions) {
+		o.storageMap = storages
+	}
+}
+
+// WithMigrationBandwidthLimit limits the migration bandwidth in KiB/s.
+func WithMigrationBandwidthLimit(kibps int) MigrateVMOption {
+	return func(o *migrateVMOptions) {
+		o.bandwidthLimit = kibps
+	}
+}
+
+// params returns the parameters of the migrate request.
+func (o migrateVMOptions) params(target string) map[string]any {
+	params := map[string]any{
+		"target": target,
+		"online": proxmox.IntOrBool(o.online),
+	}
+
+	if o.withLocalDisks {
+		params["with-local-disks"] = 1
+	}
+
+	if mapping := formatIDMap(o.storageMap, o.targetStorage); mapping != "" {
+		params["targetstorage"] = mapping
+	}
+
+	if o.bandwidthLimit > 0 {
+		params["bwlimit"] = o.bandwidthLimit
+	}
+
+	return params
+}
+
+// formatIDMap returns the Proxmox mapping of the sources to the targets, e.g. "local-lvm:local-zfs,rbd".
+// The default target, if set, applies to the sources missing from the map.
+func formatIDMap(m map[string]string, def string) string {
+	mapping := []string{}
+	for _, src := range slices.Sorted(maps.Keys(m)) {
+		mapping = append(mapping, src+":"+m[src])
+	}
+
+	if def != "" {
+		mapping = append(mapping, def)
+	}
+
+	return strings.Join(mapping, ",")
+}
+
+// mapsStorage reports whether the local disks of the storage are migrated to another storage.
+func (o migrateVMOptions) mapsStorage(check *MigrationCheck, storage string) bool {
+	if o.targetStorage == "" && o.storageMap[storage] == "" {
+		return false
+	}
+
+	return slices.ContainsFunc(check.LocalDisks, func(disk MigrationLocalDisk) bool {
+		return strings.HasPrefix(disk.VolID, storage+":")
+	})
+}
+
+// CheckVMMigration checks whether the VM can migrate to the node with the migration options.
+// The reasons it can't are listed in the Blockers of the report.
+func (c *APIClient) CheckVMMigration(ctx context.Context, vmID int, dstNode string, online bool, options ...MigrateVMOption) (_ *MigrationCheck, err error) {
+	ctx, span := c.startSpan(ctx, "CheckVMMigration", AttrVMID.Int(vmID), attribute.String("proxmox.target_node", dstNode))
+	defer func() { endSpan(span, err) }()
+
+	opts := migrateVMOptions{online: online}
+	for _, o := range options {
+		o(&opts)
+	}
+
+	vmr, err := c.GetVMByID(ctx, uint64(vmID))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.checkVMMigration(ctx, vmr.Node, vmID, dstNode, opts)
+}
+
+func (c *APIClient) checkVMMigration(ctx context.Context, node string, vmID int, dstNode string, opts migrateVMOptions) (*MigrationCheck, error) {
+	path := fmt.Sprintf("/nodes/%s/qemu/%d/migrate", node, vmID)
+	if dstNode != "" {
+		path += "?target=" + dstNode
+	}
+
+	res := migratePreconditions{}
+	if err := c.Client.Get(ctx, path, &res); err != nil {
+		return nil, fmt.Errorf("unable to check migration of vm %d: %w", vmID, err)
+	}
+
+	check := &MigrationCheck{
+		Running:             bool(res.Running),
+		AllowedNodes:        res.AllowedNodes,
+		UnavailableStorages: map[string][]string{},
+		LocalResources:      res.LocalResources,
+		MappedResources:     res.MappedResources,
+		Blockers:            []string{},
+	}
+
+	for n, reason := range res.NotAllowedNodes {
+		check.UnavailableStorages[n] = reason.UnavailableStorages
+	}
+
+	for _, disk := range res.LocalDisks {
+		check.LocalDisks = append(check.LocalDisks, MigrationLocalDisk{
+			VolID:      disk.VolID,
+			Drive:      disk.DriveName,
+			Size:       disk.Size,
+			CDROM:      bool(disk.CDROM),
+			Unused:     bool(disk.IsUnused),
+			Replicated: bool(disk.Replicated),
+		})
+	}
+
+	check.Blockers = migrationBlockers(check, node, dstNode, opts)
+
+	return check, nil
+}
+
+// migrationBlockers returns the reasons the VM can't migrate from the node to the target node with the options.
+func migrationBlockers(check *MigrationCheck, node, dstNode string, opts migrateVMOptions) []string {
+	blockers := []string{}
+
+	if dstNode == node {
+		return append(blockers, fmt.Sprintf("vm is already on node %s", dstNode))
+	}
+
+	if check.Running && !opts.online {
+		blockers = append(blockers, "vm is running, online migration is required")
+	}
+
+	// Proxmox reports the node and storage availability only for a stopped VM.
+	if !check.Running {
+		storages, denied := check.UnavailableStorages[dstNode]
+		if !denied && !slices.Contains(check.AllowedNodes, dstNode) {
+			blockers = append(blockers, fmt.Sprintf("node %s is not available", dstNode))
+		}
+
+		for _, storage := range storages {
+			if !opts.mapsStorage(check, storage) {
+				blockers = append(blockers, fmt.Sprintf("storage %s is not available on node %s", storage, dstNode))
+			}
+		}
+	}
+
+	for _, res := range check.LocalResources {
+		blockers = append(blockers, fmt.Sprintf("vm uses local resource %s", res))
+	}
+
+	for _, disk := range check.LocalDisks {
+		switch {
+		case disk.CDROM:
+			blockers = append(blockers, fmt.Sprintf("vm uses local cdrom %s", disk.VolID))
+		case check.Running && opts.online && !opts.withLocalDisks:
+			blockers = append(blockers, fmt.Sprintf("disk %s is on local storage, migrate it with WithLocalDisks", disk.VolID))
+		}
+	}
+
+	return blockers
+}
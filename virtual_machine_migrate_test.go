@@ -0,0 +1,143 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestCheckVMMigration(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddNode("pve-3")
+	srv.AddStorage("pve-3", "local-zfs", false)
+	srv.AddStorage("pve-3", "rbd", true)
+
+	srv.AddVM("pve-1", 100, map[string]any{
+		"name":  "worker-1",
+		"scsi0": "rbd:vm-100-disk-0,size=4G",
+		"scsi1": "local-lvm:vm-100-disk-1,size=10G",
+	})
+	srv.AddVM("pve-1", 101, map[string]any{
+		"name":     "worker-2",
+		"scsi0":    "rbd:vm-101-disk-0,size=4G",
+		"hostpci0": "0000:01:00.0,pcie=1",
+		"hostpci1": "mapping=gpu",
+	})
+
+	check, err := client.CheckVMMigration(ctx, 100, "pve-2", false)
+	require.NoError(t, err)
+	assert.False(t, check.Running)
+	assert.Equal(t, []string{"pve-2"}, check.AllowedNodes)
+	assert.Equal(t, map[string][]string{"pve-3": {"local-lvm"}}, check.UnavailableStorages)
+	assert.Equal(t, []goproxmox.MigrationLocalDisk{{VolID: "local-lvm:vm-100-disk-1", Drive: "scsi1", Size: 10 << 30}}, check.LocalDisks)
+	assert.Empty(t, check.Blockers)
+
+	check, err = client.CheckVMMigration(ctx, 100, "pve-3", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"storage local-lvm is not available on node pve-3"}, check.Blockers)
+
+	check, err = client.CheckVMMigration(ctx, 100, "pve-3", false, goproxmox.WithTargetStorage("local-zfs"))
+	require.NoError(t, err)
+	assert.Empty(t, check.Blockers)
+
+	check, err = client.CheckVMMigration(ctx, 101, "pve-2", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hostpci0"}, check.LocalResources)
+	assert.Equal(t, []string{"hostpci1"}, check.MappedResources)
+	assert.Equal(t, []string{"vm uses local resource hostpci0"}, check.Blockers)
+
+	err = client.MigrateVMByID(ctx, 101, "pve-2", false)
+	assert.ErrorIs(t, err, goproxmox.ErrMigrationBlocked)
+	assert.ErrorContains(t, err, "vm uses local resource hostpci0")
+}
+
+func TestMigrateVMByID_LocalDisks(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddNode("pve-3")
+	srv.AddStorage("pve-3", "local-zfs", false)
+	srv.AddStorage("pve-3", "rbd", true)
+
+	srv.AddVM("pve-1", 100, map[string]any{
+		"name":  "worker-1",
+		"scsi0": "rbd:vm-100-disk-0,size=4G",
+		"scsi1": "local-lvm:vm-100-disk-1,size=10G",
+	})
+
+	_, err := client.StartVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	err = client.MigrateVMByID(ctx, 100, "pve-3", false, goproxmox.WithLocalDisks())
+	assert.ErrorIs(t, err, goproxmox.ErrMigrationBlocked)
+	assert.ErrorContains(t, err, "online migration is required")
+
+	err = client.MigrateVMByID(ctx, 100, "pve-3", true, goproxmox.WithTargetStorage("local-zfs"))
+	assert.ErrorIs(t, err, goproxmox.ErrMigrationBlocked)
+	assert.ErrorContains(t, err, "disk local-lvm:vm-100-disk-1 is on local storage, migrate it with WithLocalDisks")
+
+	require.NoError(t, client.MigrateVMByID(ctx, 100, "pve-3", true,
+		goproxmox.WithLocalDisks(),
+		goproxmox.WithStorageMap(map[string]string{"local-lvm": "local-zfs"}),
+		goproxmox.WithMigrationBandwidthLimit(102400),
+	))
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "pve-3", vm.Node)
+	assert.Equal(t, "rbd:vm-100-disk-0,size=4G", vm.Config["scsi0"])
+	assert.Equal(t, "local-zfs:vm-100-disk-1,size=10G", vm.Config["scsi1"])
+}
+
+func TestMigrateVMByID_Online(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{
+		"name":  "worker-1",
+		"scsi0": "rbd:vm-100-disk-0,size=4G",
+	})
+
+	_, err := client.StartVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	check, err := client.CheckVMMigration(ctx, 100, "pve-2", true)
+	require.NoError(t, err)
+	assert.True(t, check.Running)
+	assert.Empty(t, check.AllowedNodes)
+	assert.Empty(t, check.Blockers)
+
+	require.NoError(t, client.MigrateVMByID(ctx, 100, "pve-2", true))
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "pve-2", vm.Node)
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+)
+
+const (
+	// defaultShutdownTimeout is the time the guest has to power off, if ShutdownOptions has no timeout.
+	defaultShutdownTimeout = 3 * time.Minute
+	// shutdownWaitMargin is the time the shutdown task may take after the guest timeout.
+	shutdownWaitMargin = 30 * time.Second
+)
+
+// ShutdownOptions configures the shutdown of a VM.
+type ShutdownOptions struct {
+	// Timeout is the time the guest has to power off, the default is 3 minutes.
+	// The wait for the shutdown task is limited by the OperationShutdown timeout,
+	// which is extended to the guest timeout and a margin if it is shorter.
+	Timeout time.Duration
+	// ForceStop makes the shutdown task stop the VM if the guest does not power off within the timeout.
+	ForceStop bool
+}
+
+// ShutdownVMByID shuts down the guest OS of a VM by its ID.
+//
+// Proxmox asks the guest to power off through the QEMU guest agent if it is
+// enabled in the VM config, otherwise it sends an ACPI power button event.
+// A stopped VM is left as is.
+func (c *APIClient) ShutdownVMByID(ctx context.Context, nodeName string, vmID int, opts ShutdownOptions) (err error) {
+	ctx, span := c.startSpan(ctx, "ShutdownVMByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.ShutdownVMByIDAsync(ctx, nodeName, vmID, opts)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// ShutdownVMByIDAsync shuts down the guest OS of a VM by its ID and returns the handle of the shutdown task.
+// The handle has no task if the VM is not running.
+func (c *APIClient) ShutdownVMByIDAsync(ctx context.Context, nodeName string, vmID int, opts ShutdownOptions) (_ *TaskHandle, err error) {
+	vm := &proxmox.VirtualMachine{}
+	vm.New(c.Client, nodeName, vmID)
+
+	if err := vm.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("unable to find vm with id %d: %w", vmID, err)
+	}
+
+	if !vm.IsRunning() {
+		h := c.newTaskHandle(OperationShutdown, vmID, "unable to shut down virtual machine", nil)
+		h.flush = false
+
+		return h, nil
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{nodeName}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationShutdown, vmID, "unable to shut down virtual machine", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	if err = c.startShutdown(ctx, h, vm, opts); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// startShutdown starts the shutdown task of the VM with the handle.
+func (c *APIClient) startShutdown(ctx context.Context, h *TaskHandle, vm *proxmox.VirtualMachine, opts ShutdownOptions) error {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	params := map[string]any{"timeout": int(timeout.Seconds())}
+	if opts.ForceStop {
+		params["forceStop"] = 1
+	}
+
+	if err := h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/status/shutdown", vm.Node, vm.VMID), params, &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	}); err != nil {
+		return fmt.Errorf("failed to shut down vm %d: %w", vm.VMID, err)
+	}
+
+	// The shutdown task runs until the guest timeout, the wait has to outlast it.
+	if limit := c.timeouts[OperationShutdown]; limit > 0 && limit < timeout+shutdownWaitMargin {
+		h.timeout = timeout + shutdownWaitMargin
+	}
+
+	return nil
+}
+
+// SuspendOptions configures the suspension of a VM.
+type SuspendOptions struct {
+	// ToDisk saves the VM state to disk and stops the VM (hibernation), instead of pausing it in RAM.
+	ToDisk bool
+	// StateStorage is the storage of the VM state if ToDisk is set, the default is chosen by Proxmox.
+	StateStorage string
+}
+
+// powerAction is a power operation of a VM and the QMP status the VM has after it.
+type powerAction struct {
+	op        Operation
+	action    string
+	params    map[string]any
+	qmpStatus string
+}
+
+// RebootVMByID reboots the guest OS of a VM by its ID and returns the VM with its config.
+func (c *APIClient) RebootVMByID(ctx context.Context, nodeName string, vmID int) (_ *proxmox.VirtualMachine, err error) {
+	ctx, span := c.startSpan(ctx, "RebootVMByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.RebootVMByIDAsync(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	return c.waitPowerAction(ctx, h, nodeName, vmID)
+}
+
+// RebootVMByIDAsync reboots the guest OS of a VM by its ID and returns the handle of the reboot task.
+func (c *APIClient) RebootVMByIDAsync(ctx context.Context, nodeName string, vmID int) (*TaskHandle, error) {
+	return c.changePowerStateAsync(ctx, nodeName, vmID, powerAction{op: OperationReboot, action: "reboot", qmpStatus: "running"})
+}
+
+// ResetVMByID resets a VM by its ID, without shutting down the guest OS, and returns the VM with its config.
+func (c *APIClient) ResetVMByID(ctx context.Context, nodeName string, vmID int) (_ *proxmox.VirtualMachine, err error) {
+	ctx, span := c.startSpan(ctx, "ResetVMByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.ResetVMByIDAsync(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	return c.waitPowerAction(ctx, h, nodeName, vmID)
+}
+
+// ResetVMByIDAsync resets a VM by its ID and returns the handle of the reset task.
+func (c *APIClient) ResetVMByIDAsync(ctx context.Context, nodeName string, vmID int) (*TaskHandle, error) {
+	return c.changePowerStateAsync(ctx, nodeName, vmID, powerAction{op: OperationReset, action: "reset", qmpStatus: "running"})
+}
+
+// SuspendVMByID suspends a VM by its ID to RAM or to disk and returns the VM with its config.
+// A VM suspended to disk is stopped and locked with VMLockSuspended until it is resumed.
+func (c *APIClient) SuspendVMByID(ctx context.Context, nodeName string, vmID int, opts SuspendOptions) (_ *proxmox.VirtualMachine, err error) {
+	ctx, span := c.startSpan(ctx, "SuspendVMByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.SuspendVMByIDAsync(ctx, nodeName, vmID, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	return c.waitPowerAction(ctx, h, nodeName, vmID)
+}
+
+// SuspendVMByIDAsync suspends a VM by its ID and returns the handle of the suspend task.
+func (c *APIClient) SuspendVMByIDAsync(ctx context.Context, nodeName string, vmID int, opts SuspendOptions) (*TaskHandle, error) {
+	a := powerAction{op: OperationSuspend, action: "suspend", qmpStatus: "paused"}
+
+	if opts.ToDisk {
+		a.params = map[string]any{"todisk": 1}
+		a.qmpStatus = "stopped"
+
+		if opts.StateStorage != "" {
+			a.params["statestorage"] = opts.StateStorage
+		}
+	}
+
+	return c.changePowerStateAsync(ctx, nodeName, vmID, a)
+}
+
+// ResumeVMByID resumes a VM suspended to RAM or to disk by its ID and returns the VM with its config.
+func (c *APIClient) ResumeVMByID(ctx context.Context, nodeName string, vmID int) (_ *proxmox.VirtualMachine, err error) {
+	ctx, span := c.startSpan(ctx, "ResumeVMByID", AttrNode.String(nodeName), AttrVMID.Int(vmID))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.ResumeVMByIDAsync(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	return c.waitPowerAction(ctx, h, nodeName, vmID)
+}
+
+// ResumeVMByIDAsync resumes a suspended VM by its ID and returns the handle of the resume task.
+// A VM suspended to disk is resumed by starting it.
+func (c *APIClient) ResumeVMByIDAsync(ctx context.Context, nodeName string, vmID int) (*TaskHandle, error) {
+	lock, err := c.GetVMLock(ctx, nodeName, vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	a := powerAction{op: OperationResume, action: "resume", qmpStatus: "running"}
+	if lock == VMLockSuspended {
+		a.action = "start"
+	}
+
+	return c.changePowerStateAsync(ctx, nodeName, vmID, a)
+}
+
+// changePowerStateAsync starts the power action of the VM and returns the handle of its task.
+// Wait checks that the VM has the QMP status of the action after the task.
+func (c *APIClient) changePowerStateAsync(ctx context.Context, nodeName string, vmID int, a powerAction) (_ *TaskHandle, err error) {
+	vm := &proxmox.VirtualMachine{}
+	vm.New(c.Client, nodeName, vmID)
+
+	if err := vm.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("unable to find vm with id %d: %w", vmID, err)
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{nodeName}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(a.op, vmID, fmt.Sprintf("unable to %s virtual machine", a.op), release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/status/%s", nodeName, vmID, a.action), a.params, &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to %s vm %d: %w", a.op, vmID, err)
+	}
+
+	h.then = func(ctx context.Context) error {
+		if err := vm.Ping(ctx); err != nil {
+			return fmt.Errorf("failed to get status of vm %d: %w", vmID, err)
+		}
+
+		if vm.QMPStatus != a.qmpStatus {
+			return fmt.Errorf("vm %d has qmpstatus %q after %s, expected %q: %w", vmID, vm.QMPStatus, a.op, a.qmpStatus, ErrUnexpectedStatus)
+		}
+
+		return nil
+	}
+
+	return h, nil
+}
+
+// waitPowerAction waits for the power action of the handle and returns the VM with its config.
+func (c *APIClient) waitPowerAction(ctx context.Context, h *TaskHandle, nodeName string, vmID int) (*proxmox.VirtualMachine, error) {
+	if err := h.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.getVM(ctx, nodeName, vmID)
+}
+
+// getVM returns the status and the config of the VM on the node.
+func (c *APIClient) getVM(ctx context.Context, nodeName string, vmID int) (*proxmox.VirtualMachine, error) {
+	vm := &proxmox.VirtualMachine{}
+	vm.New(c.Client, nodeName, vmID)
+
+	if err := vm.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("unable to find vm with id %d: %w", vmID, err)
+	}
+
+	if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", nodeName, vmID), &vm.VirtualMachineConfig); err != nil {
+		return nil, err
+	}
+
+	return vm, nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+	"github.com/sergelogvinov/go-proxmox/goproxmoxtest"
+)
+
+func lastTaskTypes(srv *goproxmoxtest.Server, n int) []string {
+	res := []string{}
+
+	tasks := srv.Tasks()
+	for _, task := range tasks[max(len(tasks)-n, 0):] {
+		res = append(res, task.Type)
+	}
+
+	return res
+}
+
+func TestShutdownVMByID(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "agent": "1"})
+
+	_, err := client.StartVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	srv.FailTask("qmshutdown", "VM quit/powerdown failed - got timeout")
+
+	err = client.ShutdownVMByID(ctx, "pve-1", 100, goproxmox.ShutdownOptions{Timeout: time.Second})
+	assert.ErrorContains(t, err, "got timeout")
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "running", vm.Status)
+
+	srv.FailTask("qmshutdown", "VM quit/powerdown failed - got timeout")
+
+	// The shutdown task stops the VM itself.
+	require.NoError(t, client.ShutdownVMByID(ctx, "pve-1", 100, goproxmox.ShutdownOptions{Timeout: time.Second, ForceStop: true}))
+	assert.Equal(t, []string{"qmshutdown", "qmshutdown"}, lastTaskTypes(srv, 2))
+
+	tasks := srv.Tasks()
+	assert.Contains(t, tasks[len(tasks)-1].Log, "VM still running - terminating now with SIGTERM")
+
+	vm, ok = srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "stopped", vm.Status)
+
+	// A stopped VM is left as is.
+	require.NoError(t, client.ShutdownVMByID(ctx, "pve-1", 100, goproxmox.ShutdownOptions{}))
+	assert.Len(t, srv.Tasks(), len(tasks))
+}
+
+func TestShutdownVMByID_LongTimeout(t *testing.T) {
+	t.Parallel()
+
+	// The guest timeout is longer than the shutdown operation timeout.
+	srv, client := newTestCluster(t, goproxmox.WithOperationTimeout(goproxmox.OperationShutdown, 100*time.Millisecond))
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	_, err := client.StartVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	srv.TaskDuration = 300 * time.Millisecond
+	srv.FailTask("qmshutdown", "VM quit/powerdown failed - got timeout")
+
+	require.NoError(t, client.ShutdownVMByID(ctx, "pve-1", 100, goproxmox.ShutdownOptions{Timeout: time.Second, ForceStop: true}))
+	assert.Equal(t, []string{"qmstart", "qmshutdown"}, lastTaskTypes(srv, 2))
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "stopped", vm.Status)
+}
+
+func TestDeleteVMByID_GracefulShutdown(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	_, err := client.StartVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	require.NoError(t, client.DeleteVMByID(ctx, "pve-1", 100, goproxmox.WithGracefulShutdown(goproxmox.ShutdownOptions{ForceStop: true})))
+	assert.Equal(t, []string{"qmshutdown", "qmdestroy"}, lastTaskTypes(srv, 2))
+
+	_, ok := srv.VM(100)
+	assert.False(t, ok)
+}
+
+func TestVMPowerOperations(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	_, err := client.RebootVMByID(ctx, "pve-1", 100)
+	assert.ErrorContains(t, err, "not running")
+
+	_, err = client.StartVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	vm, err := client.RebootVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, "running", vm.QMPStatus)
+	assert.Equal(t, "worker-1", vm.VirtualMachineConfig.Name)
+
+	vm, err = client.ResetVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, "running", vm.QMPStatus)
+
+	vm, err = client.SuspendVMByID(ctx, "pve-1", 100, goproxmox.SuspendOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "paused", vm.QMPStatus)
+
+	vm, err = client.ResumeVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, "running", vm.QMPStatus)
+
+	_, err = client.SuspendVMByID(ctx, "pve-1", 100, goproxmox.SuspendOptions{ToDisk: true, StateStorage: "nfs"})
+	assert.Error(t, err)
+
+	vm, err = client.SuspendVMByID(ctx, "pve-1", 100, goproxmox.SuspendOptions{ToDisk: true, StateStorage: "local-lvm"})
+	require.NoError(t, err)
+	assert.Equal(t, "stopped", vm.QMPStatus)
+
+	lock, err := client.GetVMLock(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, goproxmox.VMLockSuspended, lock)
+
+	vm, err = client.ResumeVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, "running", vm.QMPStatus)
+	assert.Equal(t, []string{"qmsuspend", "qmstart"}, lastTaskTypes(srv, 2))
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/luthermonson/go-proxmox"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RemoteEndpoint is the API endpoint of the target cluster of a remote migration.
+type RemoteEndpoint struct {
+	// Host is the address of a node of the target cluster.
+	Host string
+	// Port is the API port of the node, the default is 8006.
+	Port int
+	// APIToken is the API token of the target cluster, "user@realm!tokenid=secret".
+	APIToken string
+	// Fingerprint is the SHA-256 fingerprint of the API certificate of the node.
+	// It is required unless the certificate is trusted by the source node.
+	Fingerprint string
+}
+
+// String returns the endpoint with the secret of the API token redacted.
+func (e RemoteEndpoint) String() string {
+	token, _, _ := strings.Cut(strings.TrimPrefix(e.APIToken, "PVEAPIToken="), "=")
+
+	return e.format(token + "=<redacted>")
+}
+
+// param returns the target-endpoint parameter of the endpoint.
+func (e RemoteEndpoint) param() string {
+	return e.format(strings.TrimPrefix(e.APIToken, "PVEAPIToken="))
+}
+
+func (e RemoteEndpoint) format(token string) string {
+	opts := []string{"apitoken=PVEAPIToken=" + token, "host=" + e.Host}
+
+	if e.Fingerprint != "" {
+		opts = append(opts, "fingerprint="+e.Fingerprint)
+	}
+
+	if e.Port > 0 {
+		opts = append(opts, fmt.Sprintf("port=%d", e.Port))
+	}
+
+	return strings.Join(opts, ",")
+}
+
+// RemoteMigrateRequest is the request to migrate a VM to another cluster.
+type RemoteMigrateRequest struct {
+	// Endpoint is the API endpoint of the target cluster.
+	Endpoint RemoteEndpoint
+	// TargetVMID is the ID of the VM on the target cluster, the default is the VM ID.
+	TargetVMID int
+	// TargetBridge is the bridge on the target cluster for the networks missing from BridgeMap.
+	// The default is the bridge with the same name.
+	TargetBridge string
+	// BridgeMap maps the bridges of the VM to the bridges on the target cluster.
+	BridgeMap map[string]string
+	// TargetStorage is the storage on the target cluster for the disks missing from StorageMap.
+	// The default is the storage with the same name.
+	TargetStorage string
+	// StorageMap maps the storages of the VM disks to the storages on the target cluster.
+	StorageMap map[string]string
+	// Online migrates a running VM.
+	Online bool
+	// Delete deletes the VM and its disks from the source cluster after the migration.
+	// By default the VM is kept stopped.
+	Delete bool
+	// BandwidthLimit limits the migration bandwidth in KiB/s.
+	BandwidthLimit int
+}
+
+// params returns the parameters of the remote_migrate request.
+func (r RemoteMigrateRequest) params() map[string]any {
+	params := map[string]any{
+		"target-endpoint": r.Endpoint.param(),
+		"target-bridge":   formatIDMap(r.BridgeMap, r.TargetBridge),
+		"target-storage":  formatIDMap(r.StorageMap, r.TargetStorage),
+		"online":          proxmox.IntOrBool(r.Online),
+		"delete":          proxmox.IntOrBool(r.Delete),
+	}
+
+	// "1" maps every bridge and storage to the one with the same name.
+	for _, key := range []string{"target-bridge", "target-storage"} {
+		if params[key] == "" {
+			params[key] = "1"
+		}
+	}
+
+	if r.TargetVMID > 0 {
+		params["target-vmid"] = r.TargetVMID
+	}
+
+	if r.BandwidthLimit > 0 {
+		params["bwlimit"] = r.BandwidthLimit
+	}
+
+	return params
+}
+
+// RemoteMigrateVM migrates a VM to another cluster by its ID.
+// All the disks of the VM are copied to the storages of the target cluster.
+func (c *APIClient) RemoteMigrateVM(ctx context.Context, vmID int, req RemoteMigrateRequest) (err error) {
+	ctx, span := c.startSpan(ctx, "RemoteMigrateVM", AttrVMID.Int(vmID), attribute.String("proxmox.target_host", req.Endpoint.Host))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.RemoteMigrateVMAsync(ctx, vmID, req)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// RemoteMigrateVMAsync migrates a VM to another cluster by its ID and returns the handle of the migration task.
+func (c *APIClient) RemoteMigrateVMAsync(ctx context.Context, vmID int, req RemoteMigrateRequest) (_ *TaskHandle, err error) {
+	if req.Endpoint.Host == "" || req.Endpoint.APIToken == "" {
+		return nil, fmt.Errorf("remote endpoint host or api token is not set: %w", ErrBadRequest)
+	}
+
+	vmr, err := c.GetVMByID(ctx, uint64(vmID))
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := c.checkVMMigration(ctx, vmr.Node, vmID, "", migrateVMOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if blockers := remoteMigrationBlockers(check, req); len(blockers) > 0 {
+		return nil, fmt.Errorf("unable to migrate vm %d to %s: %w: %s", vmID, req.Endpoint.Host, ErrMigrationBlocked, strings.Join(blockers, "; "))
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{vmr.Node}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(OperationRemoteMigrate, vmID, "unable to migrate virtual machine", release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	params := req.params()
+
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/remote_migrate", vmr.Node, vmr.VMID), params, &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// remoteMigrationBlockers returns the reasons the VM can't migrate to another cluster with the request.
+// The resource mappings are defined per cluster, so the VM can't use any host device.
+func remoteMigrationBlockers(check *MigrationCheck, req RemoteMigrateRequest) []string {
+	blockers := []string{}
+
+	if check.Running && !req.Online {
+		blockers = append(blockers, "vm is running, online migration is required")
+	}
+
+	for _, res := range slices.Concat(check.LocalResources, check.MappedResources) {
+		blockers = append(blockers, fmt.Sprintf("vm uses local resource %s", res))
+	}
+
+	for _, disk := range check.LocalDisks {
+		if disk.CDROM {
+			blockers = append(blockers, fmt.Sprintf("vm uses local cdrom %s", disk.VolID))
+		}
+	}
+
+	return blockers
+}
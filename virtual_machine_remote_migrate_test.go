@@ -0,0 +1,117 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestRemoteEndpoint_String(t *testing.T) {
+	t.Parallel()
+
+	endpoint := goproxmox.RemoteEndpoint{Host: "10.0.0.1", APIToken: "root@pam!migrate=secret"}
+	assert.Equal(t, "apitoken=PVEAPIToken=root@pam!migrate=<redacted>,host=10.0.0.1", endpoint.String())
+
+	endpoint = goproxmox.RemoteEndpoint{Host: "10.0.0.1", Port: 443, APIToken: "PVEAPIToken=root@pam!migrate=secret", Fingerprint: "AB:CD"}
+	assert.Equal(t, "apitoken=PVEAPIToken=root@pam!migrate=<redacted>,host=10.0.0.1,fingerprint=AB:CD,port=443", endpoint.String())
+
+	req := goproxmox.RemoteMigrateRequest{Endpoint: endpoint, TargetVMID: 200}
+	assert.NotContains(t, fmt.Sprintf("%v %+v %v", endpoint, req, &req), "secret")
+}
+
+func TestRemoteMigrateVM(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{
+		"name":  "worker-1",
+		"scsi0": "rbd:vm-100-disk-0,size=4G",
+		"scsi1": "local-lvm:vm-100-disk-1,size=10G",
+		"net0":  "virtio=BC:24:11:00:00:01,bridge=vmbr0",
+	})
+
+	req := goproxmox.RemoteMigrateRequest{
+		Endpoint:      goproxmox.RemoteEndpoint{Host: "10.0.0.1", APIToken: "root@pam!migrate=secret"},
+		TargetVMID:    200,
+		BridgeMap:     map[string]string{"vmbr0": "vmbr1"},
+		StorageMap:    map[string]string{"local-lvm": "local-zfs"},
+		TargetStorage: "ceph",
+	}
+
+	err := client.RemoteMigrateVM(ctx, 100, goproxmox.RemoteMigrateRequest{Endpoint: goproxmox.RemoteEndpoint{Host: "10.0.0.1"}})
+	assert.ErrorIs(t, err, goproxmox.ErrBadRequest)
+
+	_, err = client.StartVMByID(ctx, "pve-1", 100)
+	require.NoError(t, err)
+
+	err = client.RemoteMigrateVM(ctx, 100, req)
+	assert.ErrorIs(t, err, goproxmox.ErrMigrationBlocked)
+	assert.ErrorContains(t, err, "online migration is required")
+
+	req.Online = true
+
+	h, err := client.RemoteMigrateVMAsync(ctx, 100, req)
+	require.NoError(t, err)
+	require.NoError(t, h.Wait(ctx))
+
+	log, err := h.Log(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, log, "remote migration of VM 100 to VM 200 on 10.0.0.1")
+	assert.Contains(t, log, "mapped: net0 from vmbr0 to vmbr1")
+	assert.Contains(t, log, "mapped: scsi0 from rbd:vm-100-disk-0 to ceph")
+	assert.Contains(t, log, "mapped: scsi1 from local-lvm:vm-100-disk-1 to local-zfs")
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "stopped", vm.Status)
+
+	req.Online = false
+	req.Delete = true
+
+	require.NoError(t, client.RemoteMigrateVM(ctx, 100, req))
+
+	_, ok = srv.VM(100)
+	assert.False(t, ok)
+}
+
+func TestRemoteMigrateVM_LocalResources(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{
+		"name":     "worker-1",
+		"scsi0":    "rbd:vm-100-disk-0,size=4G",
+		"hostpci0": "mapping=gpu",
+	})
+
+	err := client.RemoteMigrateVM(ctx, 100, goproxmox.RemoteMigrateRequest{
+		Endpoint: goproxmox.RemoteEndpoint{Host: "10.0.0.1", APIToken: "root@pam!migrate=secret"},
+	})
+	assert.ErrorIs(t, err, goproxmox.ErrMigrationBlocked)
+	assert.ErrorContains(t, err, "vm uses local resource hostpci0")
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// VMSnapshotRequest represents a request to create a snapshot of a virtual machine.
+type VMSnapshotRequest struct {
+	Name        string
+	Description string
+	// VMState includes the RAM of a running VM in the snapshot.
+	VMState bool
+}
+
+// VMSnapshot is a snapshot of a virtual machine in the snapshot tree.
+type VMSnapshot struct {
+	Name        string
+	Description string
+	// Parent is the name of the parent snapshot, or empty for a root snapshot.
+	Parent   string
+	SnapTime time.Time
+	// VMState is true if the snapshot includes the RAM of the VM.
+	VMState bool
+	// Current is true for the snapshot the current state of the VM is based on.
+	Current bool
+	// Children are the snapshots taken after this one, ordered by the creation time.
+	Children []*VMSnapshot
+}
+
+type vmSnapshotItem struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Parent      string             `json:"parent,omitempty"`
+	SnapTime    int64              `json:"snaptime,omitempty"`
+	VMState     *proxmox.IntOrBool `json:"vmstate,omitempty"`
+}
+
+// currentSnapshot is the name of the pseudo snapshot of the current VM state in the snapshot list.
+const currentSnapshot = "current"
+
+// ListVMSnapshots returns the snapshot tree of the VM, the root snapshots ordered by the creation time.
+func (c *APIClient) ListVMSnapshots(ctx context.Context, nodeName string, vmID int) ([]*VMSnapshot, error) {
+	items := []vmSnapshotItem{}
+	if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/snapshot", nodeName, vmID), &items); err != nil {
+		return nil, fmt.Errorf("unable to list snapshots of vm %d: %w", vmID, err)
+	}
+
+	snapshots := make(map[string]*VMSnapshot, len(items))
+	current := ""
+
+	for _, item := range items {
+		if item.Name == currentSnapshot {
+			current = item.Parent
+
+			continue
+		}
+
+		snapshots[item.Name] = &VMSnapshot{
+			Name:        item.Name,
+			Description: item.Description,
+			Parent:      item.Parent,
+			SnapTime:    time.Unix(item.SnapTime, 0),
+			VMState:     item.VMState != nil && bool(*item.VMState),
+		}
+	}
+
+	roots := []*VMSnapshot{}
+
+	for _, snap := range snapshots {
+		snap.Current = snap.Name == current
+
+		if parent, ok := snapshots[snap.Parent]; ok {
+			parent.Children = append(parent.Children, snap)
+		} else {
+			roots = append(roots, snap)
+		}
+	}
+
+	sortSnapshots(roots)
+
+	return roots, nil
+}
+
+func sortSnapshots(snapshots []*VMSnapshot) {
+	slices.SortFunc(snapshots, func(a, b *VMSnapshot) int {
+		return cmp.Or(a.SnapTime.Compare(b.SnapTime), cmp.Compare(a.Name, b.Name))
+	})
+
+	for _, snap := range snapshots {
+		sortSnapshots(snap.Children)
+	}
+}
+
+// CreateVMSnapshot creates a snapshot of the VM.
+func (c *APIClient) CreateVMSnapshot(ctx context.Context, nodeName string, vmID int, snapshot VMSnapshotRequest) (err error) {
+	ctx, span := c.startSpan(ctx, "CreateVMSnapshot", AttrNode.String(nodeName), AttrVMID.Int(vmID), attribute.String("proxmox.snapshot", snapshot.Name))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.CreateVMSnapshotAsync(ctx, nodeName, vmID, snapshot)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// CreateVMSnapshotAsync creates a snapshot of the VM and returns the handle of the snapshot task.
+// The running tasks of the VM are handled according to WithTaskConflictPolicy.
+func (c *APIClient) CreateVMSnapshotAsync(ctx context.Context, nodeName string, vmID int, snapshot VMSnapshotRequest) (*TaskHandle, error) {
+	params := map[string]any{"snapname": snapshot.Name}
+
+	if snapshot.Description != "" {
+		params["description"] = snapshot.Description
+	}
+
+	if snapshot.VMState {
+		params["vmstate"] = 1
+	}
+
+	return c.startSnapshotTask(ctx, nodeName, vmID, OperationSnapshot,
+		fmt.Sprintf("unable to create snapshot %s of vm %d", snapshot.Name, vmID),
+		func(ctx context.Context, upid *proxmox.UPID) error {
+			return c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/snapshot", nodeName, vmID), params, upid)
+		})
+}
+
+// RollbackVMSnapshot rolls the VM back to the snapshot.
+// The VM is stopped after the rollback, unless the snapshot includes the VM state.
+func (c *APIClient) RollbackVMSnapshot(ctx context.Context, nodeName string, vmID int, name string) (err error) {
+	ctx, span := c.startSpan(ctx, "RollbackVMSnapshot", AttrNode.String(nodeName), AttrVMID.Int(vmID), attribute.String("proxmox.snapshot", name))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.RollbackVMSnapshotAsync(ctx, nodeName, vmID, name)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// RollbackVMSnapshotAsync rolls the VM back to the snapshot and returns the handle of the rollback task.
+// The running tasks of the VM are handled according to WithTaskConflictPolicy.
+func (c *APIClient) RollbackVMSnapshotAsync(ctx context.Context, nodeName string, vmID int, name string) (*TaskHandle, error) {
+	return c.startSnapshotTask(ctx, nodeName, vmID, OperationRollback,
+		fmt.Sprintf("unable to rollback vm %d to snapshot %s", vmID, name),
+		func(ctx context.Context, upid *proxmox.UPID) error {
+			return c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/snapshot/%s/rollback", nodeName, vmID, url.PathEscape(name)), nil, upid)
+		})
+}
+
+// DeleteVMSnapshot deletes the snapshot of the VM. The child snapshots are attached to its parent.
+func (c *APIClient) DeleteVMSnapshot(ctx context.Context, nodeName string, vmID int, name string) (err error) {
+	ctx, span := c.startSpan(ctx, "DeleteVMSnapshot", AttrNode.String(nodeName), AttrVMID.Int(vmID), attribute.String("proxmox.snapshot", name))
+	defer func() { endSpan(span, err) }()
+
+	h, err := c.DeleteVMSnapshotAsync(ctx, nodeName, vmID, name)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+
+	return h.Wait(ctx)
+}
+
+// DeleteVMSnapshotAsync deletes the snapshot of the VM and returns the handle of the delete task.
+// The running tasks of the VM are handled according to WithTaskConflictPolicy.
+func (c *APIClient) DeleteVMSnapshotAsync(ctx context.Context, nodeName string, vmID int, name string) (*TaskHandle, error) {
+	return c.startSnapshotTask(ctx, nodeName, vmID, OperationSnapshotDelete,
+		fmt.Sprintf("unable to delete snapshot %s of vm %d", name, vmID),
+		func(ctx context.Context, upid *proxmox.UPID) error {
+			return c.Client.Delete(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/snapshot/%s", nodeName, vmID, url.PathEscape(name)), upid)
+		})
+}
+
+// startSnapshotTask starts a snapshot task of the VM. The start and the task are retried while the VM is locked.
+func (c *APIClient) startSnapshotTask(ctx context.Context, nodeName string, vmID int, op Operation, desc string, req func(context.Context, *proxmox.UPID) error) (_ *TaskHandle, err error) {
+	if err := c.checkTaskConflicts(ctx, nodeName, vmID, op); err != nil {
+		return nil, err
+	}
+
+	vm := &proxmox.VirtualMachine{}
+	vm.New(c.Client, nodeName, vmID)
+
+	if err := vm.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("unable to find vm with id %d: %w", vmID, err)
+	}
+
+	release, err := c.limiter.acquire(ctx, []string{nodeName}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	h := c.newTaskHandle(op, vmID, desc, release)
+	defer func() {
+		if err != nil {
+			h.finish()
+		}
+	}()
+
+	if err = h.startTask(ctx, func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := req(ctx, &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	}); err != nil {
+		return nil, fmt.Errorf("%s: %w", desc, err)
+	}
+
+	return h, nil
+}
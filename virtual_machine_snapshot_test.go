@@ -0,0 +1,96 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func snapshotNames(snapshots []*goproxmox.VMSnapshot) []string {
+	res := []string{}
+	for _, snap := range snapshots {
+		res = append(res, snap.Name)
+	}
+
+	return res
+}
+
+func TestVMSnapshots(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "memory": "1024"})
+
+	snapshots, err := client.ListVMSnapshots(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	require.NoError(t, client.CreateVMSnapshot(ctx, "pve-1", 100, goproxmox.VMSnapshotRequest{Name: "base", Description: "initial state"}))
+	require.NoError(t, client.UpdateVMByID(ctx, "pve-1", 100, map[string]interface{}{"memory": "2048"}))
+	require.NoError(t, client.CreateVMSnapshot(ctx, "pve-1", 100, goproxmox.VMSnapshotRequest{Name: "upgrade"}))
+
+	require.NoError(t, client.RollbackVMSnapshot(ctx, "pve-1", 100, "base"))
+
+	vm, ok := srv.VM(100)
+	require.True(t, ok)
+	assert.Equal(t, "1024", vm.Config["memory"])
+
+	require.NoError(t, client.CreateVMSnapshot(ctx, "pve-1", 100, goproxmox.VMSnapshotRequest{Name: "hotfix"}))
+
+	snapshots, err = client.ListVMSnapshots(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	require.Equal(t, []string{"base"}, snapshotNames(snapshots))
+	assert.Equal(t, "initial state", snapshots[0].Description)
+	assert.False(t, snapshots[0].SnapTime.IsZero())
+	assert.False(t, snapshots[0].Current)
+	require.Equal(t, []string{"hotfix", "upgrade"}, snapshotNames(snapshots[0].Children))
+	assert.Equal(t, "base", snapshots[0].Children[0].Parent)
+	assert.True(t, snapshots[0].Children[0].Current)
+
+	require.NoError(t, client.DeleteVMSnapshot(ctx, "pve-1", 100, "base"))
+
+	snapshots, err = client.ListVMSnapshots(ctx, "pve-1", 100)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hotfix", "upgrade"}, snapshotNames(snapshots))
+	assert.Empty(t, snapshots[0].Parent)
+
+	err = client.RollbackVMSnapshot(ctx, "pve-1", 100, "base")
+	assert.ErrorContains(t, err, "snapshot 'base' does not exist")
+
+	_, err = client.ListVMSnapshots(ctx, "pve-1", 101)
+	assert.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+}
+
+func TestVMSnapshots_Locked(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1", "lock": "backup"})
+
+	err := client.CreateVMSnapshot(ctx, "pve-1", 100, goproxmox.VMSnapshotRequest{Name: "base"})
+	require.ErrorIs(t, err, goproxmox.ErrLocked)
+}
@@ -18,9 +18,11 @@ package goproxmox
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/luthermonson/go-proxmox"
@@ -46,6 +48,36 @@ func GetVMSKU(vm *proxmox.VirtualMachine) string {
 	return string(sku)
 }
 
+// GetVMSerialName returns the instance name written into the VM SMBIOS serial by CloneVM.
+func GetVMSerialName(vm *proxmox.VirtualMachine) string {
+	smbios1 := VMSMBIOS{}
+	smbios1.UnmarshalString(vm.VirtualMachineConfig.SMBios1) //nolint:errcheck
+
+	return parseSMBIOSSerialName(smbios1)
+}
+
+// parseSMBIOSSerialName returns the h=<name> field of the h=<name>;i=<vmid> serial.
+func parseSMBIOSSerialName(smbios1 VMSMBIOS) string {
+	serial := smbios1.Serial
+
+	if smbios1.Base64 != nil && bool(*smbios1.Base64) {
+		b, err := base64.StdEncoding.DecodeString(serial)
+		if err != nil {
+			return ""
+		}
+
+		serial = string(b)
+	}
+
+	for _, field := range strings.Split(serial, ";") {
+		if name, ok := strings.CutPrefix(field, "h="); ok {
+			return name
+		}
+	}
+
+	return ""
+}
+
 func applyInstanceOptions(_ *proxmox.VirtualMachine, options VMCloneRequest, vmOptions []proxmox.VirtualMachineOption) []proxmox.VirtualMachineOption {
 	if options.CPU != 0 {
 		vmOptions = append(vmOptions, proxmox.VirtualMachineOption{Name: "cores", Value: fmt.Sprintf("%d", options.CPU)})
@@ -201,3 +233,95 @@ func detectBootDisk(cfg *proxmox.VirtualMachineConfig) string {
 	}
 	return ""
 }
+
+// getVMStorages returns the storages of the VM disks, excluding CD-ROM drives.
+func getVMStorages(cfg *proxmox.VirtualMachineConfig) []string {
+	if cfg == nil {
+		return nil
+	}
+
+	storages := []string{}
+
+	for _, disks := range []map[string]string{cfg.MergeIDEs(), cfg.MergeSATAs(), cfg.MergeSCSIs(), cfg.MergeVirtIOs()} {
+		for _, disk := range disks {
+			if strings.Contains(disk, "media=cdrom") {
+				continue
+			}
+
+			if storage, _, ok := strings.Cut(disk, ":"); ok && !slices.Contains(storages, storage) {
+				storages = append(storages, storage)
+			}
+		}
+	}
+
+	slices.Sort(storages)
+
+	return storages
+}
+
+// getOptionsStorages returns the storages of the disks in the VM create options.
+func getOptionsStorages(options map[string]interface{}) []string {
+	storages := []string{}
+
+	for k, v := range options {
+		disk, ok := v.(string)
+		if !ok || !isDiskKey(k) || strings.Contains(disk, "media=cdrom") {
+			continue
+		}
+
+		if storage, _, ok := strings.Cut(disk, ":"); ok && !slices.Contains(storages, storage) {
+			storages = append(storages, storage)
+		}
+	}
+
+	slices.Sort(storages)
+
+	return storages
+}
+
+// getOptionsVMID returns the vmid option, which can be an int, int64 or uint64, a float without
+// a fraction as decoded from JSON, a json.Number or a string. It is 0 if the option is not set.
+func getOptionsVMID(options map[string]interface{}) (int, error) {
+	var (
+		vmid int64
+		err  error
+	)
+
+	switch v := options["vmid"].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		vmid = int64(v)
+	case int64:
+		vmid = v
+	case uint64:
+		vmid = int64(min(v, maxVMID+1))
+	case float64:
+		vmid = int64(v)
+		if float64(vmid) != v {
+			err = strconv.ErrSyntax
+		}
+	case json.Number:
+		vmid, err = v.Int64()
+	case string:
+		vmid, err = strconv.ParseInt(v, 10, 64)
+	default:
+		err = strconv.ErrSyntax
+	}
+
+	if err != nil || vmid < minVMID || vmid > maxVMID {
+		return 0, fmt.Errorf("invalid vmid %v: %w", options["vmid"], ErrBadRequest)
+	}
+
+	return int(vmid), nil
+}
+
+func isDiskKey(key string) bool {
+	for _, prefix := range []string{"ide", "sata", "scsi", "virtio", "efidisk", "tpmstate"} {
+		if strings.HasPrefix(key, prefix) && strings.Trim(key[len(prefix):], "0123456789") == "" && len(key) > len(prefix) {
+			return true
+		}
+	}
+
+	return false
+}
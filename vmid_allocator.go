@@ -0,0 +1,459 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luthermonson/go-proxmox"
+)
+
+const (
+	minVMID = 100
+	maxVMID = 999999999
+
+	// defaultNextIDUpper is the exclusive upper bound of the next-id cluster option, if it is not set.
+	defaultNextIDUpper = 1000000
+	// maxNextIDAttempts limits the number of IDs checked by GetNextID.
+	maxNextIDAttempts = 100
+
+	vmidLeasePrefix = "vmid-lease-"
+	vmidLeaseTag    = "vmid-lease"
+	vmidClaimPrefix = "vmid-claim-"
+)
+
+// VMIDRange is an inclusive range of VM IDs.
+type VMIDRange struct {
+	Min int
+	Max int
+}
+
+// Contains returns true if the ID is in the range.
+func (r VMIDRange) Contains(vmid int) bool {
+	return vmid >= r.Min && vmid <= r.Max
+}
+
+// GetNextIDRange returns the range of VM IDs the cluster allocates, set by the next-id cluster option.
+func (c *APIClient) GetNextIDRange(ctx context.Context) (VMIDRange, error) {
+	options := struct {
+		NextID *struct {
+			Lower proxmox.StringOrInt `json:"lower"`
+			Upper proxmox.StringOrInt `json:"upper"`
+		} `json:"next-id"`
+	}{}
+
+	if err := c.Client.Get(ctx, "/cluster/options", &options); err != nil {
+		return VMIDRange{}, fmt.Errorf("unable to get cluster options: %w", err)
+	}
+
+	res := VMIDRange{Min: minVMID, Max: defaultNextIDUpper - 1}
+
+	if options.NextID != nil {
+		if options.NextID.Lower > 0 {
+			res.Min = max(int(options.NextID.Lower), minVMID)
+		}
+
+		if options.NextID.Upper > 0 {
+			res.Max = min(int(options.NextID.Upper)-1, maxVMID)
+		}
+	}
+
+	return res, nil
+}
+
+// VMIDAllocatorOption configures the VMIDAllocator.
+type VMIDAllocatorOption func(*VMIDAllocator)
+
+// WithVMIDRange adds a range of VM IDs the allocator takes the IDs from.
+// Without ranges the allocator uses the next-id range of the cluster.
+func WithVMIDRange(lower, upper int) VMIDAllocatorOption {
+	return func(a *VMIDAllocator) {
+		a.ranges = append(a.ranges, VMIDRange{Min: lower, Max: upper})
+	}
+}
+
+// WithVMIDExclusion excludes a range of VM IDs from the allocation.
+func WithVMIDExclusion(lower, upper int) VMIDAllocatorOption {
+	return func(a *VMIDAllocator) {
+		a.exclusions = append(a.exclusions, VMIDRange{Min: lower, Max: upper})
+	}
+}
+
+// WithVMIDLeaseTTL sets how long a reservation is kept before other allocators may reclaim it.
+func WithVMIDLeaseTTL(ttl time.Duration) VMIDAllocatorOption {
+	return func(a *VMIDAllocator) {
+		a.leaseTTL = ttl
+	}
+}
+
+// WithVMIDMaxAttempts sets how many reservations are tried before Reserve fails with ErrNoFreeVMID.
+func WithVMIDMaxAttempts(attempts int) VMIDAllocatorOption {
+	return func(a *VMIDAllocator) {
+		a.maxAttempts = max(attempts, 1)
+	}
+}
+
+// VMIDAllocator reserves VM IDs in the cluster, so the clients sharing the cluster
+// never get the same ID.
+//
+// An ID is reserved with a placeholder VM without disks, named vmid-lease-<expiration>.
+// The cluster filesystem creates the VM config atomically, so only one client can create it.
+// A claimed ID is recorded with a resource pool named vmid-claim-<vmid> until the VM is created.
+// Reserve deletes the placeholders and the claims left by crashed clients once the lease expired,
+// and the claims of the created VMs.
+//
+// The placeholders are tagged vmid-lease, and the VM lookups and the ResourceInformer leave them out.
+// They are still visible in the Proxmox UI and to other API clients.
+//
+// The allocator requires the privileges:
+//   - Sys.Audit on / to read the next-id cluster option,
+//   - VM.Audit and VM.Allocate on /vms to create, inspect and delete the placeholders,
+//   - Pool.Audit and Pool.Allocate on /pool to list, create and delete the claims.
+type VMIDAllocator struct {
+	client *APIClient
+
+	ranges      []VMIDRange
+	exclusions  []VMIDRange
+	leaseTTL    time.Duration
+	maxAttempts int
+}
+
+// NewVMIDAllocator returns a VM ID allocator of the client.
+func NewVMIDAllocator(client *APIClient, options ...VMIDAllocatorOption) *VMIDAllocator {
+	a := &VMIDAllocator{
+		client:      client,
+		leaseTTL:    10 * time.Minute,
+		maxAttempts: 20,
+	}
+
+	for _, o := range options {
+		o(a)
+	}
+
+	return a
+}
+
+// VMIDLease is a VM ID reserved by VMIDAllocator.
+type VMIDLease struct {
+	// VMID is the reserved ID.
+	VMID int
+	// Node is the node of the placeholder VM.
+	Node string
+	// Expires is the time after which other allocators may reclaim the ID.
+	Expires time.Time
+
+	client *APIClient
+	name   string
+	ttl    time.Duration
+}
+
+// Reserve reserves a free VM ID with a placeholder VM on the node.
+// The ID must be claimed with Claim before it is used, or released with Release on failure.
+func (a *VMIDAllocator) Reserve(ctx context.Context, node string) (_ *VMIDLease, err error) {
+	c := a.client
+
+	ctx, span := c.startSpan(ctx, "ReserveVMID", AttrNode.String(node))
+	defer func() { endSpan(span, err) }()
+
+	bounds, err := c.GetNextIDRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := a.ranges
+	if len(ranges) == 0 {
+		ranges = []VMIDRange{bounds}
+	}
+
+	resources, err := c.fetchResources(ctx, "vm")
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[int]*proxmox.ClusterResource, len(resources))
+	for _, r := range resources {
+		used[int(r.VMID)] = r
+	}
+
+	// The claims are listed after the resources, as Claim records the claim before it deletes the placeholder.
+	claimed, err := a.sweep(ctx, used)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := 0
+
+	for _, r := range ranges {
+		for vmid := max(r.Min, bounds.Min); vmid <= min(r.Max, bounds.Max) && attempts < a.maxAttempts; vmid++ {
+			if a.excluded(vmid) {
+				continue
+			}
+
+			if _, found := c.lastVMID.Get(strconv.Itoa(vmid)); found {
+				continue
+			}
+
+			if _, ok := used[vmid]; ok || claimed[vmid] {
+				continue
+			}
+
+			attempts++
+
+			lease, err := a.reserve(ctx, node, vmid)
+			if err != nil {
+				if errorIs(err, ErrConflict) {
+					continue
+				}
+
+				return nil, err
+			}
+
+			span.SetAttributes(AttrVMID.Int(vmid))
+
+			return lease, nil
+		}
+	}
+
+	return nil, ErrNoFreeVMID
+}
+
+func (a *VMIDAllocator) excluded(vmid int) bool {
+	return slices.ContainsFunc(a.exclusions, func(r VMIDRange) bool { return r.Contains(vmid) })
+}
+
+// expired returns true if the resource is a placeholder VM with an expired lease.
+func (a *VMIDAllocator) expired(r *proxmox.ClusterResource) bool {
+	if !isVMIDPlaceholder(r) {
+		return false
+	}
+
+	expires, err := strconv.ParseInt(strings.TrimPrefix(r.Name, vmidLeasePrefix), 10, 64)
+
+	return err == nil && time.Now().Unix() > expires
+}
+
+func isVMIDPlaceholder(r *proxmox.ClusterResource) bool {
+	ok, _ := ByTag(vmidLeaseTag)(r)
+
+	return ok && strings.HasPrefix(r.Name, vmidLeasePrefix)
+}
+
+// sweep deletes the expired placeholders, the expired claims and the claims of created VMs,
+// so the ones left by crashed clients do not pile up in the cluster. It returns the claimed VM IDs,
+// and removes the IDs of the deleted placeholders from the used ones.
+func (a *VMIDAllocator) sweep(ctx context.Context, used map[int]*proxmox.ClusterResource) (map[int]bool, error) {
+	c := a.client
+
+	pools := []struct {
+		PoolID  string `json:"poolid"`
+		Comment string `json:"comment"`
+	}{}
+
+	if err := c.Client.Get(ctx, "/pools", &pools); err != nil {
+		return nil, fmt.Errorf("unable to list vm id claims: %w", err)
+	}
+
+	res := map[int]bool{}
+
+	for _, pool := range pools {
+		vmid, err := strconv.Atoi(strings.TrimPrefix(pool.PoolID, vmidClaimPrefix))
+		if !strings.HasPrefix(pool.PoolID, vmidClaimPrefix) || err != nil {
+			continue
+		}
+
+		expires, err := time.Parse(time.RFC3339, pool.Comment)
+		vmr, created := used[vmid]
+
+		if err == nil && time.Now().Before(expires) && (!created || isVMIDPlaceholder(vmr)) {
+			res[vmid] = true
+
+			continue
+		}
+
+		if err := c.deleteVMIDClaim(ctx, vmid); err != nil && !errorIs(err, ErrNotFound) {
+			res[vmid] = true
+		}
+	}
+
+	for vmid, vmr := range used {
+		// The placeholder of a claimed ID is being deleted by Claim.
+		if res[vmid] || !a.expired(vmr) {
+			continue
+		}
+
+		if err := a.reclaim(ctx, vmr); err == nil {
+			delete(used, vmid)
+		}
+	}
+
+	return res, nil
+}
+
+// reserve creates the placeholder VM with the ID.
+func (a *VMIDAllocator) reserve(ctx context.Context, node string, vmid int) (*VMIDLease, error) {
+	c := a.client
+
+	release, err := c.limiter.acquire(ctx, []string{node}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	lease := &VMIDLease{
+		VMID:    vmid,
+		Node:    node,
+		Expires: time.Now().Add(a.leaseTTL).Truncate(time.Second),
+		client:  c,
+		ttl:     a.leaseTTL,
+	}
+	lease.name = fmt.Sprintf("%s%d", vmidLeasePrefix, lease.Expires.Unix())
+
+	options := map[string]any{
+		"vmid":        vmid,
+		"name":        lease.name,
+		"tags":        vmidLeaseTag,
+		"description": fmt.Sprintf("VM ID reserved until %s", lease.Expires.UTC().Format(time.RFC3339)),
+	}
+
+	var upid proxmox.UPID
+	if err := c.Client.Post(ctx, fmt.Sprintf("/nodes/%s/qemu", node), options, &upid); err != nil {
+		return nil, fmt.Errorf("unable to reserve vm id %d: %w", vmid, err)
+	}
+
+	c.lastVMID.Set(strconv.Itoa(vmid), struct{}{}, c.vmidTTL)
+	c.flushResources("vm")
+
+	if err := c.waitTask(ctx, proxmox.NewTask(upid, c.Client), OperationCreate); err != nil {
+		return nil, fmt.Errorf("unable to reserve vm id %d: %w", vmid, err)
+	}
+
+	return lease, nil
+}
+
+// reclaim deletes the placeholder VM of an expired lease.
+func (a *VMIDAllocator) reclaim(ctx context.Context, r *proxmox.ClusterResource) error {
+	lease := &VMIDLease{VMID: int(r.VMID), Node: r.Node, client: a.client, name: r.Name}
+
+	return lease.delete(ctx)
+}
+
+// Claim removes the placeholder VM, so the ID can be used to create or clone a VM.
+// The ID stays reserved for the other allocators until the VM is created or the lease TTL passes,
+// and in the client for the time set with WithVMIDTTL.
+//
+// Another client can take the ID between Claim and the creation of the VM only if
+// it does not use VMIDAllocator, the creation then fails with ErrConflict.
+func (l *VMIDLease) Claim(ctx context.Context) (int, error) {
+	c := l.client
+
+	err := c.createVMIDClaim(ctx, l.VMID, time.Now().Add(l.ttl))
+	if errorIs(err, ErrConflict) {
+		// The placeholder belongs to the lease, so the claim is left over from a previous use of the ID.
+		if err = c.deleteVMIDClaim(ctx, l.VMID); err == nil {
+			err = c.createVMIDClaim(ctx, l.VMID, time.Now().Add(l.ttl))
+		}
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := l.delete(ctx); err != nil {
+		if claimErr := c.deleteVMIDClaim(ctx, l.VMID); claimErr != nil {
+			return 0, errors.Join(err, claimErr)
+		}
+
+		return 0, err
+	}
+
+	l.client.lastVMID.Set(strconv.Itoa(l.VMID), struct{}{}, l.client.vmidTTL)
+
+	return l.VMID, nil
+}
+
+// Release removes the placeholder VM and frees the ID.
+func (l *VMIDLease) Release(ctx context.Context) error {
+	if err := l.delete(ctx); err != nil {
+		return err
+	}
+
+	l.client.lastVMID.Delete(strconv.Itoa(l.VMID))
+
+	return nil
+}
+
+// delete deletes the placeholder VM, if it still belongs to the lease.
+func (l *VMIDLease) delete(ctx context.Context) error {
+	c := l.client
+
+	cfg := proxmox.VirtualMachineConfig{}
+	if err := c.Client.Get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/config", l.Node, l.VMID), &cfg); err != nil {
+		if errorIs(err, ErrVirtualMachineNotFound) {
+			return fmt.Errorf("lease of vm id %d lost: %w", l.VMID, ErrConflict)
+		}
+
+		return err
+	}
+
+	if cfg.Name != l.name {
+		return fmt.Errorf("lease of vm id %d lost: %w", l.VMID, ErrConflict)
+	}
+
+	if err := c.runLocked(ctx, OperationDelete, func(ctx context.Context) (*proxmox.Task, error) {
+		var upid proxmox.UPID
+		if err := c.Client.Delete(ctx, fmt.Sprintf("/nodes/%s/qemu/%d", l.Node, l.VMID), &upid); err != nil {
+			return nil, err
+		}
+
+		return proxmox.NewTask(upid, c.Client), nil
+	}); err != nil {
+		return fmt.Errorf("unable to release vm id %d: %w", l.VMID, err)
+	}
+
+	c.flushResources("vm")
+
+	return nil
+}
+
+// createVMIDClaim records the claim of the VM ID in the cluster until the expiration.
+func (c *APIClient) createVMIDClaim(ctx context.Context, vmid int, expires time.Time) error {
+	params := map[string]any{
+		"poolid":  fmt.Sprintf("%s%d", vmidClaimPrefix, vmid),
+		"comment": expires.UTC().Truncate(time.Second).Format(time.RFC3339),
+	}
+
+	if err := c.Client.Post(ctx, "/pools", params, nil); err != nil {
+		return fmt.Errorf("unable to claim vm id %d: %w", vmid, err)
+	}
+
+	return nil
+}
+
+// deleteVMIDClaim deletes the claim of the VM ID.
+func (c *APIClient) deleteVMIDClaim(ctx context.Context, vmid int) error {
+	if err := c.Client.Delete(ctx, fmt.Sprintf("/pools/%s%d", vmidClaimPrefix, vmid), nil); err != nil {
+		return fmt.Errorf("unable to delete claim of vm id %d: %w", vmid, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package goproxmox_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	goproxmox "github.com/sergelogvinov/go-proxmox"
+)
+
+func TestVMIDAllocator_Reserve(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.SetNextIDRange(100, 200)
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	bounds, err := client.GetNextIDRange(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, goproxmox.VMIDRange{Min: 100, Max: 199}, bounds)
+
+	alloc := goproxmox.NewVMIDAllocator(client, goproxmox.WithVMIDRange(100, 1000), goproxmox.WithVMIDExclusion(101, 102))
+
+	lease, err := alloc.Reserve(ctx, "pve-1")
+	require.NoError(t, err)
+	assert.Equal(t, 103, lease.VMID)
+
+	vm, ok := srv.VM(103)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(vm.Config["name"].(string), "vmid-lease-"))
+
+	other, err := alloc.Reserve(ctx, "pve-1")
+	require.NoError(t, err)
+	assert.Equal(t, 104, other.VMID)
+
+	require.NoError(t, other.Release(ctx))
+
+	_, ok = srv.VM(104)
+	assert.False(t, ok)
+
+	vmid, err := lease.Claim(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 103, vmid)
+
+	_, err = client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: vmid, Name: "worker-2"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, lease.Release(ctx), goproxmox.ErrConflict)
+}
+
+func TestVMIDAllocator_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := newTestCluster(t)
+	ctx := context.Background()
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		vmids = map[int]int{}
+	)
+
+	// Each client has its own local VM ID cache, like the replicas of a controller.
+	for range 3 {
+		client, err := goproxmox.NewAPIClientWithOptions(srv.URL())
+		require.NoError(t, err)
+
+		alloc := goproxmox.NewVMIDAllocator(client, goproxmox.WithVMIDRange(200, 299))
+
+		for range 5 {
+			wg.Go(func() {
+				lease, err := alloc.Reserve(ctx, "pve-1")
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				mu.Lock()
+				vmids[lease.VMID]++
+				mu.Unlock()
+			})
+		}
+	}
+
+	wg.Wait()
+
+	assert.Len(t, vmids, 15)
+
+	for vmid, count := range vmids {
+		assert.Equal(t, 1, count, "vm id %d reserved more than once", vmid)
+	}
+}
+
+func TestVMIDAllocator_Reclaim(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddVM("pve-1", 300, map[string]any{"name": "vmid-lease-1", "tags": "vmid-lease"})
+	srv.AddVM("pve-1", 301, map[string]any{"name": "worker-1"})
+
+	alloc := goproxmox.NewVMIDAllocator(client, goproxmox.WithVMIDRange(300, 301))
+
+	lease, err := alloc.Reserve(ctx, "pve-1")
+	require.NoError(t, err)
+	assert.Equal(t, 300, lease.VMID)
+
+	_, err = alloc.Reserve(ctx, "pve-1")
+	assert.ErrorIs(t, err, goproxmox.ErrNoFreeVMID)
+}
+
+func TestVMIDAllocator_Sweep(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	// Left by a crashed client, outside of the range the allocator takes the IDs from.
+	srv.AddVM("pve-1", 700, map[string]any{"name": "vmid-lease-1", "tags": "vmid-lease"})
+	srv.AddVM("pve-1", 701, map[string]any{"name": fmt.Sprintf("vmid-lease-%d", time.Now().Add(time.Minute).Unix()), "tags": "vmid-lease"})
+	srv.AddPool("vmid-claim-702", time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+
+	alloc := goproxmox.NewVMIDAllocator(client, goproxmox.WithVMIDRange(600, 609))
+
+	lease, err := alloc.Reserve(ctx, "pve-1")
+	require.NoError(t, err)
+	assert.Equal(t, 600, lease.VMID)
+
+	_, ok := srv.VM(700)
+	assert.False(t, ok)
+
+	_, ok = srv.VM(701)
+	assert.True(t, ok)
+
+	assert.Empty(t, srv.Pools())
+}
+
+func TestVMIDAllocator_HidePlaceholders(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	srv.AddVM("pve-1", 100, map[string]any{"name": "worker-1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	informer := client.NewResourceInformer(time.Hour, "vm")
+	go informer.Run(ctx)
+
+	require.Eventually(t, informer.HasSynced, 5*time.Second, 10*time.Millisecond)
+
+	alloc := goproxmox.NewVMIDAllocator(client, goproxmox.WithVMIDRange(200, 300))
+
+	lease, err := alloc.Reserve(ctx, "pve-1")
+	require.NoError(t, err)
+	assert.Equal(t, 200, lease.VMID)
+
+	require.NoError(t, informer.Resync(ctx))
+
+	_, ok := informer.Store().GetByVMID(200)
+	assert.False(t, ok)
+
+	_, err = client.GetVMByID(ctx, 200)
+	assert.ErrorIs(t, err, goproxmox.ErrVirtualMachineNotFound)
+
+	vms, err := client.GetVMsByFilter(ctx)
+	require.NoError(t, err)
+	require.Len(t, vms, 1)
+	assert.Equal(t, uint64(100), vms[0].VMID)
+}
+
+func TestVMIDAllocator_ClaimAcrossClients(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	other, err := goproxmox.NewAPIClientWithOptions(srv.URL(), goproxmox.WithTaskPollInterval(10*time.Millisecond))
+	require.NoError(t, err)
+
+	alloc := goproxmox.NewVMIDAllocator(client, goproxmox.WithVMIDRange(400, 499))
+	otherAlloc := goproxmox.NewVMIDAllocator(other, goproxmox.WithVMIDRange(400, 499))
+
+	lease, err := alloc.Reserve(ctx, "pve-1")
+	require.NoError(t, err)
+
+	vmid, err := lease.Claim(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 400, vmid)
+	assert.Contains(t, srv.Pools(), "vmid-claim-400")
+
+	// The placeholder is gone, but the claim keeps the ID reserved until the VM is created.
+	otherLease, err := otherAlloc.Reserve(ctx, "pve-2")
+	require.NoError(t, err)
+	assert.Equal(t, 401, otherLease.VMID)
+
+	_, err = client.CloneVM(ctx, 9000, goproxmox.VMCloneRequest{Node: "pve-1", NewID: vmid, Name: "worker-1"})
+	require.NoError(t, err)
+
+	otherLease, err = otherAlloc.Reserve(ctx, "pve-2")
+	require.NoError(t, err)
+	assert.Equal(t, 402, otherLease.VMID)
+	assert.NotContains(t, srv.Pools(), "vmid-claim-400")
+}
+
+func TestVMIDAllocator_ReclaimClaim(t *testing.T) {
+	t.Parallel()
+
+	srv, client := newTestCluster(t)
+	ctx := context.Background()
+
+	srv.AddPool("vmid-claim-500", time.Now().Add(-time.Minute).UTC().Format(time.RFC3339))
+	srv.AddPool("vmid-claim-501", time.Now().Add(time.Minute).UTC().Format(time.RFC3339))
+
+	alloc := goproxmox.NewVMIDAllocator(client, goproxmox.WithVMIDRange(500, 501))
+
+	lease, err := alloc.Reserve(ctx, "pve-1")
+	require.NoError(t, err)
+	assert.Equal(t, 500, lease.VMID)
+	assert.Equal(t, []string{"vmid-claim-501"}, slices.Collect(maps.Keys(srv.Pools())))
+
+	_, err = alloc.Reserve(ctx, "pve-1")
+	assert.ErrorIs(t, err, goproxmox.ErrNoFreeVMID)
+}